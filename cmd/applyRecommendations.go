@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/apply"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/paradise"
+	"k8stools/pkg/patch"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/simulate"
+	"k8stools/pkg/trend"
+)
+
+// --dry-run 的取值
+const (
+	dryRunServer = "server"
+	dryRunNone   = "none"
+)
+
+var (
+	applySource  string
+	applyDryRun  string
+	applyConfirm bool
+	// applyOverwrite 以当前资源覆盖已有的修改前记录
+	applyOverwrite bool
+)
+
+// applyRecommendationsCmd represents the apply-recommendations command
+var applyRecommendationsCmd = &cobra.Command{
+	Use:   "apply-recommendations",
+	Short: "通过 API Server 将资源建议写回工作负载",
+	Long: `将 paradise（或 trend）给出的建议写回工作负载的 Pod 模板。默认只做 server-side dry-run：
+先以 dry-run 修改工作负载，再以修改后的 Pod 模板 dry-run 创建 Pod，逐个报告准入 webhook、LimitRange、ResourceQuota 的拒绝原因。
+只有加上 --confirm 才会真正修改预检通过的工作负载，修改前的资源记录在注解 k8stools.io/previous-resources 中，可用 rollback 恢复。
+多次应用时默认保留已有记录，rollback 恢复到第一次修改前的值；--overwrite-previous 以当前资源重新记录。
+--confirm 时可用 --dry-run=none 跳过预检；显式指定 --dry-run=server 时即使加了 --confirm 也只做预检。
+--source trend 的 Prometheus 查询不区分集群，只能写回 --context 选中的单个集群，不支持 --all-clusters。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(simulate.Sources, applySource) {
+			return fmt.Errorf("不支持的建议来源: %s (请使用 %s)", applySource, strings.Join(simulate.Sources, "/"))
+		}
+		if applyDryRun != dryRunServer && applyDryRun != dryRunNone {
+			return fmt.Errorf("不支持的 --dry-run: %s (请使用 %s/%s)", applyDryRun, dryRunServer, dryRunNone)
+		}
+		if applyDryRun == dryRunNone && !applyConfirm {
+			return fmt.Errorf("--dry-run=none 会直接修改集群，需要同时指定 --confirm")
+		}
+		if fromSnapshot != "" {
+			return fmt.Errorf("离线快照不支持 apply-recommendations")
+		}
+		if applySource == simulate.SourceTrend {
+			if err := rejectAllClusters("apply-recommendations --source trend"); err != nil {
+				return err
+			}
+		}
+		dryRun := !applyConfirm || (cmd.Flags().Changed("dry-run") && applyDryRun == dryRunServer)
+
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if applySource == simulate.SourceTrend {
+			if err := config.Validate(c, "trend"); err != nil {
+				return err
+			}
+		}
+		opts, err := samplingOptions(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			fmt.Fprintln(os.Stderr, "⚠️ 已指定 --confirm，预检通过的工作负载将被修改并触发滚动更新")
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]apply.Result, error) {
+			changes, recErr := recommendationChanges(cmd.Context(), c, kc, applySource, opts)
+			targets, err := patch.Targets(cmd.Context(), kc, changes)
+			results := apply.Apply(cmd.Context(), kc, targets, apply.Options{DryRun: dryRun, SkipCheck: applyDryRun == dryRunNone, OverwritePrevious: applyOverwrite})
+			return results, errors.Join(recErr, err)
+		})
+		return render(rows, "apply_recommendations", err)
+	},
+}
+
+// recommendationChanges 按来源生成单个集群的建议并转换为容器变更
+func recommendationChanges(ctx context.Context, c *config.Config, kc kube.Client, source string, opts sampling.Options) ([]patch.Change, error) {
+	if source == simulate.SourceTrend {
+		advice, err := trend.GetTrend(ctx, c, kc)
+		return trendChanges(advice), err
+	}
+	advice, err := paradise.GetParadise(ctx, c, kc, opts)
+	return paradiseChanges(advice), err
+}
+
+func init() {
+	rootCmd.AddCommand(applyRecommendationsCmd)
+	addSamplingFlags(applyRecommendationsCmd)
+
+	applyRecommendationsCmd.Flags().StringVar(&applySource, "source", simulate.SourceParadise, "建议来源: "+strings.Join(simulate.Sources, "/"))
+	applyRecommendationsCmd.Flags().StringVar(&applyDryRun, "dry-run", dryRunServer, "server：只做 server-side dry-run；none：配合 --confirm 跳过预检直接修改")
+	applyRecommendationsCmd.Flags().BoolVar(&applyConfirm, "confirm", false, "真正修改预检通过的工作负载")
+	applyRecommendationsCmd.Flags().BoolVar(&applyOverwrite, "overwrite-previous", false, "以当前资源覆盖已有的 k8stools.io/previous-resources 记录，默认保留第一次修改前的值")
+}
@@ -0,0 +1,19 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "配置文件管理",
+	Long:  `配置文件相关的子命令，如生成、查看、校验配置文件。`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
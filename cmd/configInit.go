@@ -0,0 +1,210 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/prometheus"
+)
+
+// config init 的参数
+var (
+	initInteractive bool
+	initForce       bool
+)
+
+// configInitCmd represents the config init command
+var configInitCmd = &cobra.Command{
+	Use:   "init [配置文件]",
+	Short: "生成带注释的配置文件",
+	Long: `从 kubeconfig 读取 context，连接集群发现命名空间，可选探测 Prometheus 地址，
+生成覆盖 cost、resourceAdvisor 全部字段且带注释的配置文件（默认 config.yaml）。
+  -i 交互式逐项确认；非交互模式下未指定 --namespace 时使用集群中的全部业务命名空间。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := "config.yaml"
+		if len(args) > 0 {
+			target = args[0]
+		}
+		if _, err := os.Stat(target); err == nil && !initForce {
+			return fmt.Errorf("%s 已存在，如需覆盖请加 --force", target)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		c := config.Default()
+		c.KubeConfig = kubeconfig
+		c.Prometheus = prometheusURL
+
+		in := bufio.NewReader(cmd.InOrStdin())
+		p := prompter{in: in, out: cmd.ErrOrStderr(), enabled: initInteractive}
+
+		// context
+		contexts, current, err := kube.ListContexts(c.KubeConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ %v，将使用 in-cluster 配置\n", err)
+		} else {
+			c.Context = kubeContext
+			if c.Context == "" {
+				c.Context = p.choose("选择 context", contexts, current)
+			}
+		}
+
+		// namespace
+		c.NameSpace = namespaces
+		if len(c.NameSpace) == 0 {
+			c.NameSpace = discoverNamespaces(cmd.Context(), c, p)
+		}
+		if len(c.NameSpace) == 0 {
+			fmt.Fprintln(os.Stderr, "⚠️ 未获取到命名空间，已写入 default，请按需修改")
+			c.NameSpace = []string{"default"}
+		}
+
+		// prometheus
+		c.Prometheus = p.ask("Prometheus 地址（留空跳过）", c.Prometheus)
+		if c.Prometheus != "" {
+			if v, err := prometheus.Probe(cmd.Context(), c.Prometheus); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️ Prometheus 探测失败: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "✅ Prometheus 可用，版本 %s\n", v)
+			}
+		}
+
+		// cost
+		c.Cost.CpuPrice = p.askInt("单台机器价格（元）", c.Cost.CpuPrice)
+		c.Cost.TotalCpu = p.askInt("单台机器 CPU 核数", c.Cost.TotalCpu)
+		c.Cost.MemPrice = p.askFloat("每 GiB 内存价格（元，0 表示不计内存成本）", c.Cost.MemPrice)
+		c.Cost.Model = p.choose("成本计费口径", config.CostModels, c.Cost.Model)
+
+		var buf bytes.Buffer
+		if err := config.WriteTemplate(&buf, c); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("写入配置文件失败: %w", err)
+		}
+		fmt.Printf("✅ 已生成配置文件 %s\n", target)
+		if err := config.Validate(c); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ %v\n", err)
+		}
+		return nil
+	},
+}
+
+// discoverNamespaces 连接集群获取命名空间，默认选中 kube-*、default 以外的全部命名空间
+func discoverNamespaces(ctx context.Context, c *config.Config, p prompter) []string {
+	var all, selected []string
+	kc, err := kube.NewClient(c)
+	if err == nil {
+		all, err = kube.ListNamespaces(ctx, kc)
+	}
+	if err != nil {
+		// 发现失败时交互模式下仍可手动输入
+		fmt.Fprintf(os.Stderr, "⚠️ 发现命名空间失败: %v\n", err)
+	}
+	for _, ns := range all {
+		if !kube.IsSystemNamespace(ns) {
+			selected = append(selected, ns)
+		}
+	}
+	return p.chooseMany("选择命名空间", all, selected)
+}
+
+// prompter 交互式输入，enabled 为 false 时直接返回默认值
+type prompter struct {
+	in      *bufio.Reader
+	out     io.Writer
+	enabled bool
+}
+
+func (p prompter) ask(question, def string) string {
+	if !p.enabled {
+		return def
+	}
+	fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	line, _ := p.in.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return def
+}
+
+func (p prompter) askInt(question string, def int) int {
+	for {
+		answer := p.ask(question, strconv.Itoa(def))
+		n, err := strconv.Atoi(answer)
+		if err == nil {
+			return n
+		}
+		fmt.Fprintf(p.out, "❌ 请输入整数\n")
+	}
+}
+
+func (p prompter) askFloat(question string, def float64) float64 {
+	for {
+		answer := p.ask(question, strconv.FormatFloat(def, 'f', -1, 64))
+		f, err := strconv.ParseFloat(answer, 64)
+		if err == nil {
+			return f
+		}
+		fmt.Fprintf(p.out, "❌ 请输入数字\n")
+	}
+}
+
+// choose 从列表中选择一项，可输入序号或名称
+func (p prompter) choose(question string, options []string, def string) string {
+	if !p.enabled || len(options) == 0 {
+		return def
+	}
+	for i, o := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, o)
+	}
+	return pick(p.ask(question, def), options)
+}
+
+// chooseMany 从列表中选择多项，以逗号分隔序号或名称；列表为空时可直接输入名称
+func (p prompter) chooseMany(question string, options, def []string) []string {
+	if !p.enabled {
+		return def
+	}
+	for i, o := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, o)
+	}
+	answer := p.ask(question+"（逗号分隔）", strings.Join(def, ","))
+	var result []string
+	for _, s := range strings.Split(answer, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, pick(s, options))
+		}
+	}
+	return result
+}
+
+// pick 输入为合法序号时返回对应选项，否则原样返回
+func pick(answer string, options []string) string {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1]
+	}
+	return answer
+}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+
+	configInitCmd.Flags().BoolVarP(&initInteractive, "interactive", "i", false, "交互式逐项确认")
+	configInitCmd.Flags().BoolVar(&initForce, "force", false, "覆盖已存在的配置文件")
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/output"
+	"sigs.k8s.io/yaml"
+)
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "输出配置文件的 JSON Schema",
+	Long: `根据配置结构生成 JSON Schema，包含字段说明和默认值（-o yaml 输出 YAML），可供编辑器补全和校验 config.yaml。
+例如：k8stools config schema --out-file config.schema.json
+然后在 config.yaml 首行加入：# yaml-language-server: $schema=./config.schema.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema := config.Schema()
+
+		var data []byte
+		var err error
+		if format == output.FormatYAML {
+			data, err = yaml.Marshal(schema)
+		} else {
+			data, err = json.MarshalIndent(schema, "", "  ")
+			data = append(data, '\n')
+		}
+		if err != nil {
+			return fmt.Errorf("生成 JSON Schema 失败: %w", err)
+		}
+		filename := "config.schema.json"
+		if format == output.FormatYAML {
+			filename = "config.schema.yaml"
+		}
+		return writeOutput(cmd, data, filename)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+)
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [子命令...]",
+	Short: "校验配置文件",
+	Long: `校验配置文件中的通用字段，并检查指定子命令所需的必填字段，所有问题一次性列出。
+例如：k8stools config validate trend costEstimator -f config.yaml
+未指定子命令时只检查通用规则；配置文件中的未知字段会被视为错误并给出拼写建议。`,
+	ValidArgs: []string{"cpu", "poderrors", "costEstimator", "paradise", "runtimeInspect", "trend", "resourceAdvisor", "snapshot", "nodes", "simulate", "apply-recommendations", "rollback", "quota"},
+	Args:      cobra.OnlyValidArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 校验时未知字段（多为拼写错误）同样视为错误
+		opts := loadOptions(cmd)
+		opts.Strict = true
+		c, err := config.Load(opts)
+		if err != nil {
+			return err
+		}
+		if err := config.Validate(c, args...); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 配置校验通过: %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/output"
+	"sigs.k8s.io/yaml"
+)
+
+// configViewCmd represents the config view command
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "查看实际生效的配置",
+	Long: `读取配置文件并填充默认值后输出实际生效的配置，未填写的系数会显示为各模块使用的默认值。
+默认输出 YAML，-o json 输出 JSON。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := readConfig(cmd)
+		if err != nil {
+			return err
+		}
+		config.ApplyDefaults(c)
+
+		var data []byte
+		if format == output.FormatJSON {
+			data, err = json.MarshalIndent(c, "", "  ")
+			data = append(data, '\n')
+		} else {
+			data, err = yaml.Marshal(c)
+		}
+		if err != nil {
+			return fmt.Errorf("序列化配置失败: %w", err)
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configViewCmd)
+
+}
@@ -4,29 +4,45 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
-	"k8stools/internal/costEstimator"
-	"k8stools/pkg/config"
+	"k8stools/pkg/costEstimator"
+	"k8stools/pkg/kube"
 
 	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
 )
 
 // costEstimatorCmd represents the costEstimator command
 var costEstimatorCmd = &cobra.Command{
 	Use:   "costEstimator",
 	Short: "成本估算",
-	Long:  `成本估算`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	Long: `按容器估算 CPU 与内存成本：CPU 单价为 cost.cpuPrice / cost.totalCpu（每核），内存单价为 cost.memPrice（每 GiB）。
+计费口径由 cost.model 决定：request（按 Requests）、usage（按使用量）、max（取两者较大值）；
+使用量来自 metrics-server（可配合 --sample-duration 采样取平均）或 Prometheus 窗口内的平均值（cost.source: prometheus）。
+Idle Cost 为 Requests 超出使用量部分的成本，与计费口径无关；原生 sidecar（restartPolicy: Always 的 init 容器）同样计入。
+获取不到使用量（如未部署 metrics-server）时按 Requests 计费，Idle Cost 记为 0，并在说明列注明。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		opts, err := samplingOptions(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		costEstimator.GetCostEstimate(c)
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]costEstimator.ContainerCost, error) {
+			return costEstimator.GetCostEstimate(cmd.Context(), c, kc, opts)
+		})
+		return render(rows, "cost_estimate", err)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(costEstimatorCmd)
+	addSamplingFlags(costEstimatorCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -37,5 +53,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// costEstimatorCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	costEstimatorCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
@@ -4,28 +4,40 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
-	"k8stools/internal/cpu"
 	"k8stools/pkg/config"
+	"k8stools/pkg/cpu"
+	"k8stools/pkg/kube"
 )
 
 // cpuCmd represents the cpu command
 var cpuCmd = &cobra.Command{
 	Use:   "cpu",
-	Short: "获取k8s的cpu使用情况",
-	Long:  `获取 k8s 当前使用情况，获取的是瞬时值可以配合监控参考。`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	Short: "获取k8s的cpu与内存使用情况",
+	Long:  `获取 k8s 当前 CPU 与内存的使用量、Requests/Limits 及 Requests 利用率，默认获取的是瞬时值，可通过 --sample-duration 在一段时间内多次采样并统计 min/avg/p95/max。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		cpu.GetDeploymentCpu(c)
+		opts, err := samplingOptions(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]cpu.DeploymentCPU, error) {
+			return cpu.GetDeploymentCpu(cmd.Context(), c, kc, opts)
+		})
+		return render(rows, "deployment_cpu_info", err)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(cpuCmd)
+	addSamplingFlags(cpuCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -36,5 +48,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// cpuCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	cpuCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
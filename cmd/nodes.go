@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/nodes"
+)
+
+var nodesGroupBy string
+
+// nodesCmd represents the nodes command
+var nodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "节点与节点池的资源分配情况",
+	Long: `统计每个节点或节点池（--group-by pool）的 Allocatable、Requests、Limits 与实际使用量（CPU/内存/Pod 数），
+并给出 Limits 超卖比例。Requests/Limits 来自节点上全部未结束的 Pod，不受 namespace 配置限制；
+节点池按配置 nodePoolLabel（或 --node-pool-label）指定的标签划分，未配置时自动识别 GKE/EKS/Karpenter/AKS 的节点池标签，
+没有节点池标签的节点归入 (unlabeled)。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if nodesGroupBy != nodes.GroupByNode && nodesGroupBy != nodes.GroupByPool {
+			return fmt.Errorf("不支持的汇总维度: %s (请使用 %s/%s)", nodesGroupBy, nodes.GroupByNode, nodes.GroupByPool)
+		}
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		var rows []nodes.NodeUsage
+		var errs []error
+		for _, kc := range clients {
+			r, err := nodes.GetNodes(cmd.Context(), c, kc, nodesGroupBy)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("集群 %s: %w", kc.Name(), err))
+			}
+			rows = append(rows, r...)
+		}
+		return render(rows, "node_capacity", errors.Join(errs...))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodesCmd)
+
+	nodesCmd.Flags().StringVar(&nodesGroupBy, "group-by", nodes.GroupByNode, "汇总维度: node/pool")
+	nodesCmd.Flags().String("node-pool-label", "", "区分节点池的节点标签，覆盖配置 nodePoolLabel")
+}
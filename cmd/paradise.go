@@ -4,28 +4,71 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
+	"errors"
+
 	"github.com/spf13/cobra"
-	"k8stools/internal/paradise"
 	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/paradise"
+	"k8stools/pkg/patch"
 )
 
 // paradiseCmd represents the paradise command
 var paradiseCmd = &cobra.Command{
 	Use:   "paradise",
 	Short: "k8s理想情况分配",
-	Long:  `根据特定规则，对k8s的pod资源进行调整`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	Long: `根据特定规则，对k8s的pod资源进行调整，可通过 --sample-duration 基于一段时间内的多次采样给出建议
+--emit-patches 将建议值写成可直接应用的 patch 文件（strategic 或 kustomize 格式）及 resources.diff`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		opts, err := samplingOptions(cmd)
+		if err != nil {
+			return err
+		}
+		patchDir, patchFormat, err := patchOptions(cmd)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		paradise.GetParadise(c)
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]paradise.ResourceAdvice, error) {
+			rows, err := paradise.GetParadise(cmd.Context(), c, kc, opts)
+			if patchDir != "" && len(rows) > 0 {
+				err = errors.Join(err, emitPatches(cmd.Context(), kc, patchDir, patchFormat, paradiseChanges(rows), len(clients) > 1))
+			}
+			return rows, err
+		})
+		return render(rows, "pod_resource_advice", err)
 	},
 }
 
+// paradiseChanges 将 paradise 的建议转换为容器变更
+func paradiseChanges(rows []paradise.ResourceAdvice) []patch.Change {
+	changes := make([]patch.Change, 0, len(rows))
+	for _, r := range rows {
+		changes = append(changes, patch.Change{
+			Namespace:  r.Namespace,
+			Kind:       r.Kind,
+			Workload:   r.Workload,
+			Container:  r.Container,
+			CPURequest: r.CPURequest,
+			CPULimit:   r.CPULimit,
+			MemRequest: r.MemRequest,
+			MemLimit:   r.MemLimit,
+		})
+	}
+	return changes
+}
+
 func init() {
 	rootCmd.AddCommand(paradiseCmd)
+	addSamplingFlags(paradiseCmd)
+	addPatchFlags(paradiseCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -36,5 +79,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// paradiseCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	paradiseCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
@@ -4,10 +4,10 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
-	"k8stools/internal/poderrors"
 	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/poderrors"
 )
 
 // poderrorsCmd represents the poderrors command
@@ -15,13 +15,19 @@ var poderrorsCmd = &cobra.Command{
 	Use:   "poderrors",
 	Short: "异常检查",
 	Long:  `检测异常 Pod 状态（CrashLoop、ImagePull 等）`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		poderrors.GetPodError(c)
-
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]poderrors.PodError, error) {
+			return poderrors.GetPodError(cmd.Context(), c, kc)
+		})
+		return render(rows, "pod_error_report", err)
 	},
 }
 
@@ -37,5 +43,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// poderrorsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	poderrorsCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/quota"
+)
+
+// quotaCmd represents the quota command
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "命名空间 ResourceQuota 使用情况",
+	Long: `列出配置的命名空间下每个 ResourceQuota 各配额项的已用量、上限与使用率，使用率达到 90% 标记为接近上限，
+没有 ResourceQuota 的命名空间单独列出一行；LimitRanges 列为该命名空间的 LimitRange 数量。
+paradise 与 trend 的建议会按同样的 LimitRange 修正，并在“合规说明”列中提示预计超出的配额。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]quota.Usage, error) {
+			return quota.GetQuota(cmd.Context(), c, kc)
+		})
+		return render(rows, "namespace_quota", err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+}
@@ -6,23 +6,38 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
-	"k8stools/internal/resourceAdvisor"
-	"k8stools/pkg/config"
+	"k8stools/pkg/resourceAdvisor"
+	"time"
 )
 
 // resourceAdvisorCmd represents the resourceAdvisor command
 var resourceAdvisorCmd = &cobra.Command{
 	Use:   "resourceAdvisor",
 	Short: "资源顾问",
-	Long:  `根据监控信息，估算项目资源分配情况`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	Long: `根据监控信息，估算项目资源分配情况
+Prometheus 查询不区分集群，只分析 --context 选中的单个集群，不支持 --all-clusters`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rejectAllClusters("resourceAdvisor"); err != nil {
+			return err
+		}
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		cluster, err := clusterName(c)
+		if err != nil {
+			return err
+		}
+		c, err = resolveNamespaces(cmd.Context(), c)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		if err := resourceAdvisor.ResourceAdvisor(c); err != nil {
-			fmt.Printf("❌ 资源顾问分析失败: %v\n", err)
+		rows, err := resourceAdvisor.ResourceAdvisor(cmd.Context(), c, cluster)
+		if err != nil && len(rows) == 0 {
+			return fmt.Errorf("资源顾问分析失败: %w", err)
 		}
+		// 文件名带时间戳，便于对比多次分析结果
+		return render(rows, "resource_advice_"+time.Now().Format("2006-01-02_150405"), err)
 	},
 }
 
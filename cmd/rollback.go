@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8stools/pkg/apply"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+)
+
+var (
+	rollbackSelector string
+	rollbackConfirm  bool
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "恢复 apply-recommendations 修改前的容器资源",
+	Long: `读取 apply-recommendations 写入的注解 k8stools.io/previous-resources，将工作负载的容器资源恢复为修改前的值并删除该注解。
+默认只输出当前值与恢复值的 diff（标准错误）并做 server-side dry-run，加上 --confirm 才会真正恢复。
+可用 --namespace 与 -l/--selector（工作负载标签）限定范围。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rollbackSelector != "" {
+			if _, err := labels.Parse(rollbackSelector); err != nil {
+				return fmt.Errorf("标签选择器格式错误: %w", err)
+			}
+		}
+		if fromSnapshot != "" {
+			return fmt.Errorf("离线快照不支持 rollback")
+		}
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]apply.RollbackResult, error) {
+			var results []apply.RollbackResult
+			var errs []error
+			for _, ns := range c.NameSpace {
+				rollbacks, err := apply.FindRollbacks(cmd.Context(), kc, ns, rollbackSelector)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				for i := range rollbacks {
+					fmt.Fprint(os.Stderr, rollbacks[i].Target.Diff())
+				}
+				results = append(results, apply.RestoreAll(cmd.Context(), kc, rollbacks, !rollbackConfirm)...)
+			}
+			return results, errors.Join(errs...)
+		})
+		if len(rows) == 0 && err == nil {
+			fmt.Fprintln(os.Stderr, "✅ 没有找到需要回滚的工作负载")
+			return nil
+		}
+		return render(rows, "rollback", err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().StringVarP(&rollbackSelector, "selector", "l", "", "工作负载标签选择器，如 app=web")
+	rollbackCmd.Flags().BoolVar(&rollbackConfirm, "confirm", false, "真正恢复，默认只预览 diff 并做 server-side dry-run")
+}
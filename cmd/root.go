@@ -4,26 +4,67 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/output"
+	"k8stools/pkg/patch"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/snapshot"
 )
 
-var path string
+// 配置相关的全局参数，与配置字段同名的参数会覆盖配置文件和 K8STOOLS_* 环境变量
+var (
+	path          string
+	kubeconfig    string
+	namespaces    []string
+	prometheusURL string
+	allNamespaces bool
+)
+
+// 集群相关的全局参数
+var (
+	kubeContext  string
+	allClusters  bool
+	fromSnapshot string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "k8stools",
 	Short: "k8s 小工具",
 	Long:  `k8s 日常使用小工具`,
+	// 错误由 Execute 统一输出，参数错误以外不打印用法
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//Run: func(cmd *cobra.Command, args []string) {
 	//
 	//},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, f := range output.Formats {
+			if f == format {
+				return nil
+			}
+		}
+		return fmt.Errorf("不支持的输出格式: %s (请使用 %s)", format, strings.Join(output.Formats, "/"))
+	},
 }
-var format string
+
+// 输出相关的全局参数
+var (
+	format  string
+	outFile string
+	outDir  string
+)
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
@@ -31,10 +72,12 @@ func Execute() {
 	err := rootCmd.Execute()
 
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Println("🎯 默认使用 config.yaml 文件，可通过 -f 指定其他配置")
-	fmt.Println("💡 示例：k8stools cpu -f config-dev.yaml")
+	// 提示信息输出到 stderr，避免污染 -o json/yaml 的标准输出
+	fmt.Fprintln(os.Stderr, "🎯 默认使用 config.yaml 文件，可通过 -f 指定其他配置")
+	fmt.Fprintln(os.Stderr, "💡 示例：k8stools cpu -f config-dev.yaml")
 
 }
 
@@ -44,8 +87,219 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.k8stools.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&path, "config", "f", "config.yaml", "指定配置文件，未显式指定且文件不存在时只使用环境变量和参数")
+	rootCmd.PersistentFlags().StringVar(&path, "file", "config.yaml", "指定配置文件")
+	rootCmd.PersistentFlags().MarkDeprecated("file", "请使用 --config/-f")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig 路径，覆盖配置中的 kubeconfig")
+	rootCmd.PersistentFlags().StringSliceVar(&namespaces, "namespace", nil, "命名空间，可重复或逗号分隔，支持通配符，覆盖配置中的 namespace")
+	rootCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "分析全部命名空间，等价于 --namespace '*'")
+	rootCmd.PersistentFlags().String("namespace-selector", "", "命名空间标签选择器，如 team=payments")
+	rootCmd.PersistentFlags().StringSlice("exclude-namespaces", nil, "排除的命名空间，支持通配符，如 kube-*")
+	rootCmd.PersistentFlags().StringVar(&prometheusURL, "prometheus", "", "Prometheus 地址，覆盖配置中的 prometheus")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "指定 kubeconfig context 或 clusters 中的集群名称")
+	rootCmd.PersistentFlags().BoolVar(&allClusters, "all-clusters", false, "对配置文件 clusters 中的所有集群执行")
+	rootCmd.PersistentFlags().StringVar(&fromSnapshot, "from-snapshot", "", "从 snapshot 命令生成的归档离线读取集群数据")
+	rootCmd.PersistentFlags().StringVarP(&format, "output", "o", output.FormatTable, "输出格式: "+strings.Join(output.Formats, "|"))
+	rootCmd.PersistentFlags().StringVar(&outFile, "out-file", "", "输出到指定文件，默认输出到标准输出")
+	rootCmd.PersistentFlags().StringVar(&outDir, "out-dir", "", "输出到指定目录，文件名按报表自动生成")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 
 }
+
+// clientErr 部分集群客户端创建失败的原因，由 render 与分析阶段的错误一起作为警告输出
+var clientErr error
+
+// newKubeClients 按全局 --context/--all-clusters 参数创建集群客户端
+// 指定 --from-snapshot 时改为从归档构建离线客户端，--context 用于筛选归档中的集群
+// 只有全部集群都创建失败时才返回错误，其余情况下失败的集群记录在 clientErr 中并跳过
+func newKubeClients(c *config.Config) ([]kube.Client, error) {
+	if fromSnapshot != "" {
+		archive, err := snapshot.Load(fromSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Clients(archive, kubeContext)
+	}
+	clients, err := kube.NewClients(c, kubeContext, allClusters)
+	if len(clients) == 0 {
+		return nil, err
+	}
+	clientErr = err
+	return clients, nil
+}
+
+// outputOptions 汇总全局输出参数
+func outputOptions() output.Options {
+	return output.Options{Format: format, OutFile: outFile, OutDir: outDir}
+}
+
+// addSamplingFlags 为基于 metrics-server 的子命令（cpu、paradise）注册采样窗口参数
+func addSamplingFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("sample-duration", 0, "在该时长内多次采样 metrics-server 并统计 min/avg/p95/max（如 10m），为 0 时只读取一次瞬时值；多集群时依次采样")
+	cmd.Flags().Duration("sample-interval", sampling.DefaultInterval, "采样间隔，需不超过 --sample-duration")
+}
+
+// samplingOptions 读取采样参数，开启采样时提示预计耗时
+func samplingOptions(cmd *cobra.Command) (sampling.Options, error) {
+	duration, _ := cmd.Flags().GetDuration("sample-duration")
+	interval, _ := cmd.Flags().GetDuration("sample-interval")
+	opts := sampling.Options{Duration: duration, Interval: interval}
+	if err := opts.Validate(); err != nil {
+		return opts, err
+	}
+	if opts.Enabled() {
+		fmt.Fprintf(os.Stderr, "⏳ 将在 %s 内每 %s 采样一次 metrics-server 数据\n", opts.Duration, opts.Interval)
+	}
+	return opts, nil
+}
+
+// addPatchFlags 为给出资源建议的子命令（paradise、trend）注册 patch 输出参数
+func addPatchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("emit-patches", "", "将建议值按工作负载写成 patch 文件到该目录，并生成调整前后资源的 resources.diff")
+	cmd.Flags().String("patch-format", patch.FormatStrategic, "patch 格式: "+strings.Join(patch.Formats, "/"))
+}
+
+// patchOptions 读取 patch 输出参数，dir 为空表示不输出 patch
+func patchOptions(cmd *cobra.Command) (dir, format string, err error) {
+	dir, _ = cmd.Flags().GetString("emit-patches")
+	format, _ = cmd.Flags().GetString("patch-format")
+	for _, f := range patch.Formats {
+		if f == format {
+			return dir, format, nil
+		}
+	}
+	return dir, format, fmt.Errorf("不支持的 patch 格式: %s (请使用 %s)", format, strings.Join(patch.Formats, "/"))
+}
+
+// emitPatches 为单个集群写出 patch，多集群时写入 dir/<集群名>；跳过的工作负载以警告错误返回
+func emitPatches(ctx context.Context, kc kube.Client, dir, format string, changes []patch.Change, multiCluster bool) error {
+	if multiCluster {
+		dir = filepath.Join(dir, kc.Name())
+	}
+	targets, skipped := patch.Targets(ctx, kc, changes)
+	files, err := patch.Write(dir, format, targets)
+	if err != nil {
+		return errors.Join(skipped, err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ 已生成 %d 个工作负载的 patch 到 %s（共 %d 个文件）\n", len(targets), dir, len(files))
+	return skipped
+}
+
+// nsResolver 本次运行共用的命名空间解析器，同一集群只解析一次
+var nsResolver = kube.NewNamespaceResolver()
+
+// collect 对每个集群执行分析并汇总结果，个别集群失败时合并错误并保留其余结果
+// 传给 fn 的配置中 namespace 已按该集群解析为实际的命名空间列表
+func collect[T any](ctx context.Context, c *config.Config, clients []kube.Client, fn func(c *config.Config, kc kube.Client) ([]T, error)) ([]T, error) {
+	var rows []T
+	var errs []error
+	for _, kc := range clients {
+		resolved, err := nsResolver.ResolveConfig(ctx, c, kc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("集群 %s: %w", kc.Name(), err))
+			continue
+		}
+		r, err := fn(resolved, kc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("集群 %s: %w", kc.Name(), err))
+		}
+		rows = append(rows, r...)
+	}
+	return rows, errors.Join(errs...)
+}
+
+// rejectAllClusters 基于 Prometheus 的命令只查询配置中的单个 Prometheus，查询不区分集群，
+// 对多个集群执行会把同一份数据归到每个集群，因此不支持 --all-clusters
+func rejectAllClusters(command string) error {
+	if allClusters {
+		return fmt.Errorf("%s 查询的 Prometheus 不区分集群，不支持 --all-clusters，请用 --context 选择单个集群", command)
+	}
+	return nil
+}
+
+// clusterName 返回 --context 选中的集群在报表中显示的名称，供不创建集群客户端的分析器使用
+func clusterName(c *config.Config) (string, error) {
+	clusters, err := kube.SelectClusters(c, kubeContext, false)
+	if err != nil {
+		return "", err
+	}
+	return kube.ClusterName(c, clusters[0]), nil
+}
+
+// resolveNamespaces 为不访问集群的分析器（trend、resourceAdvisor）解析命名空间
+// 配置中含通配符或 namespaceSelector 时使用 --context 选中的第一个集群解析
+func resolveNamespaces(ctx context.Context, c *config.Config) (*config.Config, error) {
+	if !kube.NeedsResolve(c) {
+		return nsResolver.ResolveConfig(ctx, c, nil)
+	}
+	clients, err := newKubeClients(c)
+	if err != nil {
+		return nil, err
+	}
+	return nsResolver.ResolveConfig(ctx, c, clients[0])
+}
+
+// render 按全局输出参数渲染报表，客户端创建与分析阶段的部分失败作为警告输出到 stderr
+func render(rows interface{}, name string, analyzeErr error) error {
+	analyzeErr = errors.Join(clientErr, analyzeErr)
+	if analyzeErr != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ 部分数据获取失败: %v\n", analyzeErr)
+	}
+	if err := output.Render(rows, name, outputOptions()); err != nil {
+		return fmt.Errorf("输出失败: %w", err)
+	}
+	return nil
+}
+
+// readConfig 合并配置文件、K8STOOLS_* 环境变量和全局参数
+// 未显式指定 --config 时配置文件可以不存在，便于在 CI 中只用环境变量运行
+func readConfig(cmd *cobra.Command) (*config.Config, error) {
+	c, err := config.Load(loadOptions(cmd))
+	if err != nil {
+		return nil, err
+	}
+	if allNamespaces {
+		c.NameSpace = []string{"*"}
+	}
+	return c, nil
+}
+
+// loadOptions 由全局参数生成配置来源
+func loadOptions(cmd *cobra.Command) config.LoadOptions {
+	explicit := cmd.Flags().Changed("config") || cmd.Flags().Changed("file")
+	return config.LoadOptions{Path: path, Optional: !explicit, Flags: cmd.Flags()}
+}
+
+// writeOutput 输出非报表类的原始内容（如 schema），同样支持 --out-file/--out-dir，filename 为输出到目录时的文件名
+func writeOutput(cmd *cobra.Command, data []byte, filename string) error {
+	target := outFile
+	if target == "" && outDir != "" {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		target = filepath.Join(outDir, filename)
+	}
+	if target == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return fmt.Errorf("创建输出文件失败: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ 已生成 %s\n", target)
+	return nil
+}
+
+// loadConfig 读取配置，并按当前子命令校验必填字段
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	c, err := readConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(c, cmd.Name()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
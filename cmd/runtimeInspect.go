@@ -4,10 +4,11 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
-	"k8stools/internal/runtimeInspect"
 	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/runtimeInspect"
+	"time"
 )
 
 // runtimeInspectCmd represents the runtimeInspect command
@@ -15,14 +16,20 @@ var runtimeInspectCmd = &cobra.Command{
 	Use:   "runtimeInspect",
 	Short: "采集运行中的 Pod 容器行为信息（进程、端口、环境变量）",
 	Long:  `采集运行中的 Pod 容器行为信息（进程、端口、环境变量）`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		if err := runtimeInspect.GetRuntimeInspect(c); err != nil {
-			fmt.Printf("❌ 运行时检查失败: %v\n", err)
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
 		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]runtimeInspect.ContainerRuntime, error) {
+			return runtimeInspect.GetRuntimeInspect(cmd.Context(), c, kc)
+		})
+		// 文件名带时间戳，便于对比多次快照
+		return render(rows, "runtime_snapshot_"+time.Now().Format("2006-01-02_150405"), err)
 	},
 }
 
@@ -38,5 +45,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// runtimeInspectCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	runtimeInspectCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/paradise"
+	"k8stools/pkg/simulate"
+	"k8stools/pkg/trend"
+)
+
+var simulateSource string
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "模拟按建议调整 Requests 后所需的节点数",
+	Long: `将 paradise（或 trend）给出的建议 Requests 替换到当前 Pod 上，按首次适应递减（first-fit-decreasing）装箱，
+对比调整前后所需的节点数与无法调度的 Pod。节点规格来自配置 simulate.nodeCpu/nodeMemory，未配置时使用集群现有节点；
+DaemonSet 与静态 Pod 视为节点固定开销，不考虑亲和性、污点与拓扑分布约束。
+--source trend 的 Prometheus 查询不区分集群，不支持 --all-clusters。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(simulate.Sources, simulateSource) {
+			return fmt.Errorf("不支持的建议来源: %s (请使用 %s)", simulateSource, strings.Join(simulate.Sources, "/"))
+		}
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if simulateSource == simulate.SourceTrend {
+			if err := rejectAllClusters("simulate --source trend"); err != nil {
+				return err
+			}
+			if err := config.Validate(c, "trend"); err != nil {
+				return err
+			}
+		}
+		opts, err := samplingOptions(cmd)
+		if err != nil {
+			return err
+		}
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		rows, err := collect(cmd.Context(), c, clients, func(c *config.Config, kc kube.Client) ([]simulate.Result, error) {
+			var recs simulate.Recommendations
+			var recErr error
+			switch simulateSource {
+			case simulate.SourceTrend:
+				var advice []trend.TrendAdvice
+				advice, recErr = trend.GetTrend(cmd.Context(), c, kc)
+				if advice == nil && recErr != nil {
+					return nil, recErr
+				}
+				recs = simulate.FromTrend(advice)
+			default:
+				var advice []paradise.ResourceAdvice
+				advice, recErr = paradise.GetParadise(cmd.Context(), c, kc, opts)
+				recs = simulate.FromParadise(advice)
+			}
+			r, err := simulate.Simulate(cmd.Context(), c, kc, simulateSource, recs)
+			return r, errors.Join(recErr, err)
+		})
+		return render(rows, "simulate", err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	addSamplingFlags(simulateCmd)
+
+	simulateCmd.Flags().StringVar(&simulateSource, "source", simulate.SourceParadise, "建议来源: "+strings.Join(simulate.Sources, "/"))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8stools/pkg/snapshot"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [归档文件]",
+	Short: "导出集群快照用于离线分析",
+	Long: `将分析器读取的工作负载（Deployment、StatefulSet、DaemonSet、ReplicaSet、Job、CronJob）、Pod、HPA、PodMetrics、Event、LimitRange、ResourceQuota 以及 Node、NodeMetrics 导出到单个归档文件（gzip 压缩的 JSON），
+之后可通过全局参数 --from-snapshot 在无集群访问权限的环境中运行 cpu、paradise、costEstimator、poderrors、nodes、quota（paradise 同样按快照中的 LimitRange/ResourceQuota 做合规检查）。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("k8stools-snapshot-%s.json.gz", time.Now().Format("2006-01-02_150405"))
+		if len(args) > 0 {
+			target = args[0]
+		}
+
+		clients, err := newKubeClients(c)
+		if err != nil {
+			return err
+		}
+		if clientErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ %v\n", clientErr)
+		}
+
+		archive := &snapshot.Archive{Version: snapshot.FormatVersion, CreatedAt: time.Now()}
+		for _, kc := range clients {
+			namespaces, err := nsResolver.Resolve(cmd.Context(), c, kc)
+			if err != nil {
+				return fmt.Errorf("集群 %s: %w", kc.Name(), err)
+			}
+			cl, warnings, err := snapshot.Capture(cmd.Context(), kc, namespaces)
+			for _, w := range warnings {
+				fmt.Fprintf(os.Stderr, "⚠️ 集群 %s: %v\n", kc.Name(), w)
+			}
+			if err != nil {
+				return fmt.Errorf("集群 %s 快照失败: %w", kc.Name(), err)
+			}
+			archive.Clusters = append(archive.Clusters, *cl)
+		}
+
+		if err := snapshot.Save(target, archive); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 已生成快照 %s（%d 个集群）\n", target, len(archive.Clusters))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// snapshotCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// snapshotCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -4,30 +4,85 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
-	"k8stools/internal/trend"
-	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/patch"
+	"k8stools/pkg/trend"
+	"k8stools/pkg/workload"
 )
 
 // trendCmd represents the trend command
 var trendCmd = &cobra.Command{
 	Use:   "trend",
 	Short: "基于 Prometheus 的资源使用趋势分析与建议",
-	Long:  `根据prometheus一周的策略，分析出流量趋势`,
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ReadYaml(path)
+	Long: `根据prometheus一周的策略，分析出流量趋势
+--emit-patches 将建议值写成可直接应用的 patch 文件（strategic 或 kustomize 格式）及 resources.diff，
+无法连接集群时 Kind 按 Deployment 处理，diff 中没有调整前的值。
+Prometheus 查询不区分集群，只分析 --context 选中的单个集群，不支持 --all-clusters`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rejectAllClusters("trend"); err != nil {
+			return err
+		}
+		c, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		patchDir, patchFormat, err := patchOptions(cmd)
 		if err != nil {
-			fmt.Println(err)
+			return err
+		}
+		// 集群客户端用于确定 Pod 所属的工作负载，无法连接时按 Pod 名称推测
+		var kc kube.Client
+		if clients, err := newKubeClients(c); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ 无法创建集群客户端，Workload 将按 Pod 名称推测: %v\n", err)
+		} else {
+			kc = clients[0]
 		}
-		if err := trend.GetTrend(c); err != nil {
-			fmt.Printf("❌ 趋势分析失败: %v\n", err)
+		c, err = nsResolver.ResolveConfig(cmd.Context(), c, kc)
+		if err != nil {
+			return err
 		}
+		rows, err := trend.GetTrend(cmd.Context(), c, kc)
+		if err != nil && rows == nil {
+			return err
+		}
+		if patchDir != "" && len(rows) > 0 {
+			err = errors.Join(err, emitPatches(cmd.Context(), kc, patchDir, patchFormat, trendChanges(rows), false))
+		}
+		return render(rows, "resource_trend", err)
 	},
 }
 
+// trendChanges 将 trend 的建议转换为容器变更；trend 按 Pod 输出，同一容器有多条建议时由 patch.Targets 取较大值。
+// 无法确定 Kind 时（Workload 按 Pod 名称推测）视为 Deployment
+func trendChanges(rows []trend.TrendAdvice) []patch.Change {
+	changes := make([]patch.Change, 0, len(rows))
+	for _, r := range rows {
+		kind := r.Kind
+		if kind == "" {
+			kind = workload.KindDeployment
+		}
+		changes = append(changes, patch.Change{
+			Namespace:  r.Namespace,
+			Kind:       kind,
+			Workload:   r.Workload,
+			Container:  r.Container,
+			CPURequest: int64(r.CPURequest),
+			CPULimit:   int64(r.CPULimit),
+			MemRequest: int64(r.MemRequest),
+			MemLimit:   int64(r.MemLimit),
+		})
+	}
+	return changes
+}
+
 func init() {
 	rootCmd.AddCommand(trendCmd)
+	addPatchFlags(trendCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -38,5 +93,4 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// trendCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	trendCmd.Flags().StringVarP(&path, "file", "f", "config.yaml", "指定配置文件")
 }
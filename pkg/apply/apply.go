@@ -0,0 +1,261 @@
+// Package apply 通过 API Server 将资源建议写回工作负载：默认以 server-side dry-run 预检，
+// 并以 dry-run 创建 Pod 检查 LimitRange、ResourceQuota 与准入 webhook；确认后才真正修改，
+// 同时在工作负载注解中记录修改前的资源以便回滚
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8stools/pkg/kube"
+	"k8stools/pkg/patch"
+	"k8stools/pkg/workload"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 记录在工作负载上的注解
+const (
+	// AnnotationPreviousResources 修改前各容器的资源（JSON，见 Previous），rollback 据此恢复
+	AnnotationPreviousResources = "k8stools.io/previous-resources"
+	// AnnotationAppliedAt 最近一次应用建议的时间（RFC3339）
+	AnnotationAppliedAt = "k8stools.io/applied-at"
+)
+
+// FieldManager 修改工作负载时使用的 field manager
+const FieldManager = "k8stools"
+
+// 检查阶段
+const (
+	StageWorkload = "workload"
+	StagePod      = "pod"
+)
+
+// 拒绝原因分类
+const (
+	ReasonLimitRange    = "LimitRange"
+	ReasonResourceQuota = "ResourceQuota"
+	ReasonWebhook       = "AdmissionWebhook"
+	ReasonInvalid       = "Invalid"
+	ReasonForbidden     = "Forbidden"
+	ReasonOther         = "Other"
+)
+
+// Previous 修改前的容器资源，按容器名记录
+type Previous struct {
+	Containers     map[string]corev1.ResourceRequirements `json:"containers,omitempty"`
+	InitContainers map[string]corev1.ResourceRequirements `json:"initContainers,omitempty"`
+}
+
+// Result 单个工作负载的预检或修改结果
+type Result struct {
+	Cluster    string `json:"cluster" header:"Cluster"`
+	Namespace  string `json:"namespace" header:"Namespace"`
+	Kind       string `json:"kind" header:"Kind"`
+	Workload   string `json:"workload" header:"Workload"`
+	Containers int    `json:"containers" header:"容器数"`
+	DryRun     bool   `json:"dryRun" header:"DryRun"`
+	Status     string `json:"status" header:"状态"`
+	Stage      string `json:"stage" header:"阶段"`
+	Reason     string `json:"reason" header:"拒绝原因"`
+	Message    string `json:"message" header:"详情"`
+}
+
+// 结果状态
+const (
+	StatusPassed   = "✅ 预检通过"
+	StatusApplied  = "✅ 已修改"
+	StatusRejected = "❌ 被拒绝"
+)
+
+// Options 修改方式
+type Options struct {
+	// DryRun 为 true 时只做 server-side dry-run 预检，不修改集群
+	DryRun bool
+	// SkipCheck 为 true 时跳过预检直接修改，DryRun 为 true 时无效
+	SkipCheck bool
+	// OverwritePrevious 为 true 时以当前资源覆盖已有的 AnnotationPreviousResources 记录；
+	// 默认保留已有记录（只补充其中没有的容器），多次应用后仍可回滚到第一次修改前的值
+	OverwritePrevious bool
+}
+
+// Apply 依次修改 targets 中的工作负载，返回每个工作负载的结果
+// 每个工作负载先以 dry-run 修改，再以返回的 Pod 模板 dry-run 创建 Pod（LimitRange、ResourceQuota 只作用于 Pod）；
+// 非 dry-run 时只有预检通过的工作负载才会真正修改，并记录修改前的资源到 AnnotationPreviousResources
+// （已有记录时按 opts.OverwritePrevious 保留或覆盖，已有记录格式错误且未指定覆盖时拒绝修改）
+func Apply(ctx context.Context, kc kube.Client, targets []patch.Target, opts Options) []Result {
+	clientset := kc.Kubernetes()
+	var results []Result
+	for i := range targets {
+		t := &targets[i]
+		r := Result{
+			Cluster:    kc.Name(),
+			Namespace:  t.Namespace,
+			Kind:       t.Kind,
+			Workload:   t.Name,
+			Containers: len(t.Containers),
+			DryRun:     opts.DryRun,
+		}
+		data, err := patchData(t, opts.OverwritePrevious)
+		if err != nil {
+			results = append(results, reject(r, StageWorkload, err))
+			continue
+		}
+		if opts.DryRun || !opts.SkipCheck {
+			if stage, err := check(ctx, clientset, t.Namespace, t.Kind, t.Name, data); err != nil {
+				results = append(results, reject(r, stage, err))
+				continue
+			}
+		}
+		if opts.DryRun {
+			r.Status = StatusPassed
+			results = append(results, r)
+			continue
+		}
+		if _, err := Patch(ctx, clientset, t.Namespace, t.Kind, t.Name, data, false); err != nil {
+			results = append(results, reject(r, StageWorkload, err))
+			continue
+		}
+		r.Status = StatusApplied
+		results = append(results, r)
+	}
+	return results
+}
+
+// check 以 dry-run 修改工作负载，并以修改后的 Pod 模板 dry-run 创建 Pod，返回失败的阶段
+func check(ctx context.Context, clientset kubernetes.Interface, ns, kind, name string, data []byte) (string, error) {
+	tpl, err := Patch(ctx, clientset, ns, kind, name, data, true)
+	if err != nil {
+		return StageWorkload, err
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: name + "-k8stools-dryrun-",
+			Namespace:    ns,
+			Labels:       tpl.Labels,
+			Annotations:  tpl.Annotations,
+		},
+		Spec: tpl.Spec,
+	}
+	if _, err := clientset.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}, FieldManager: FieldManager}); err != nil {
+		return StagePod, err
+	}
+	return "", nil
+}
+
+// patchData 生成修改容器资源并记录修改前资源的 strategic merge patch
+// 工作负载上已有 AnnotationPreviousResources 时保留其中的记录，只补充尚未记录的容器；overwrite 为 true 时以当前资源重新记录
+func patchData(t *patch.Target, overwrite bool) ([]byte, error) {
+	prev := Previous{}
+	if recorded, ok := t.Annotations[AnnotationPreviousResources]; ok && !overwrite {
+		if err := json.Unmarshal([]byte(recorded), &prev); err != nil {
+			return nil, fmt.Errorf("已有注解 %s 格式错误（可用 --overwrite-previous 以当前资源重新记录）: %w", AnnotationPreviousResources, err)
+		}
+	}
+	for name := range t.Containers {
+		before := t.Before[name]
+		if t.Init[name] {
+			if prev.InitContainers == nil {
+				prev.InitContainers = make(map[string]corev1.ResourceRequirements)
+			}
+			if _, ok := prev.InitContainers[name]; !ok {
+				prev.InitContainers[name] = before
+			}
+		} else {
+			if prev.Containers == nil {
+				prev.Containers = make(map[string]corev1.ResourceRequirements)
+			}
+			if _, ok := prev.Containers[name]; !ok {
+				prev.Containers[name] = before
+			}
+		}
+	}
+	recorded, err := json.Marshal(prev)
+	if err != nil {
+		return nil, fmt.Errorf("序列化修改前的资源失败: %w", err)
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				AnnotationPreviousResources: string(recorded),
+				AnnotationAppliedAt:         time.Now().Format(time.RFC3339),
+			},
+		},
+		"spec": t.Spec(),
+	})
+}
+
+// Patch 以 strategic merge patch 修改工作负载，返回修改后（dry-run 时为预期）的 Pod 模板
+func Patch(ctx context.Context, clientset kubernetes.Interface, ns, kind, name string, data []byte, dryRun bool) (*corev1.PodTemplateSpec, error) {
+	opts := metav1.PatchOptions{FieldManager: FieldManager}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	pt := types.StrategicMergePatchType
+	switch kind {
+	case workload.KindDeployment:
+		obj, err := clientset.AppsV1().Deployments(ns).Patch(ctx, name, pt, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &obj.Spec.Template, nil
+	case workload.KindStatefulSet:
+		obj, err := clientset.AppsV1().StatefulSets(ns).Patch(ctx, name, pt, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &obj.Spec.Template, nil
+	case workload.KindDaemonSet:
+		obj, err := clientset.AppsV1().DaemonSets(ns).Patch(ctx, name, pt, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &obj.Spec.Template, nil
+	case workload.KindReplicaSet:
+		obj, err := clientset.AppsV1().ReplicaSets(ns).Patch(ctx, name, pt, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &obj.Spec.Template, nil
+	case workload.KindCronJob:
+		obj, err := clientset.BatchV1().CronJobs(ns).Patch(ctx, name, pt, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &obj.Spec.JobTemplate.Spec.Template, nil
+	}
+	return nil, fmt.Errorf("不支持修改 %s", kind)
+}
+
+func reject(r Result, stage string, err error) Result {
+	r.Status = StatusRejected
+	r.Stage = stage
+	r.Reason = Classify(err)
+	r.Message = err.Error()
+	return r
+}
+
+// Classify 按 API Server 返回的错误判断拒绝原因
+func Classify(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "exceeded quota") || strings.Contains(msg, "must specify limits") || strings.Contains(msg, "must specify requests"):
+		return ReasonResourceQuota
+	case strings.Contains(msg, "per Container") || strings.Contains(msg, "per Pod") || strings.Contains(msg, "LimitRange"):
+		return ReasonLimitRange
+	case strings.Contains(msg, "admission webhook"):
+		return ReasonWebhook
+	case apierrors.IsInvalid(err):
+		return ReasonInvalid
+	case apierrors.IsForbidden(err):
+		return ReasonForbidden
+	}
+	return ReasonOther
+}
@@ -0,0 +1,150 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/kube"
+	"k8stools/pkg/patch"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testClient(annotations map[string]string) kube.Client {
+	return kube.NewForClients("test", fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web", Annotations: annotations},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		}}},
+	}), nil, nil)
+}
+
+// applyCPU 以集群当前状态生成 app 容器 CPU Request 的变更并直接修改（fake clientset 不支持 dry-run，跳过预检）
+func applyCPU(t *testing.T, kc kube.Client, cpu int64, overwrite bool) Result {
+	t.Helper()
+	targets, err := patch.Targets(context.Background(), kc, []patch.Change{
+		{Namespace: "demo", Kind: "Deployment", Workload: "web", Container: "app", CPURequest: cpu},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Apply(context.Background(), kc, targets, Options{SkipCheck: true, OverwritePrevious: overwrite})
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	return results[0]
+}
+
+func getWeb(t *testing.T, kc kube.Client) *appsv1.Deployment {
+	t.Helper()
+	d, err := kc.Kubernetes().AppsV1().Deployments("demo").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// recordedCPU 返回注解中记录的 app 容器 CPU Request
+func recordedCPU(t *testing.T, d *appsv1.Deployment) string {
+	t.Helper()
+	var prev Previous
+	if err := json.Unmarshal([]byte(d.Annotations[AnnotationPreviousResources]), &prev); err != nil {
+		t.Fatalf("注解 %s: %v", AnnotationPreviousResources, err)
+	}
+	q := prev.Containers["app"].Requests[corev1.ResourceCPU]
+	return q.String()
+}
+
+func TestApplyTwiceThenRollback(t *testing.T) {
+	kc := testClient(nil)
+	for _, cpu := range []int64{300, 400} {
+		if r := applyCPU(t, kc, cpu, false); r.Status != StatusApplied {
+			t.Fatalf("apply %dm: %s %s", cpu, r.Status, r.Message)
+		}
+	}
+
+	d := getWeb(t, kc)
+	if got := d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "400m" {
+		t.Errorf("requests.cpu = %s, want 400m", got)
+	}
+	// 第二次应用保留第一次修改前的记录
+	if got := recordedCPU(t, d); got != "200m" {
+		t.Errorf("记录的 requests.cpu = %s, want 200m", got)
+	}
+
+	rollbacks, err := FindRollbacks(context.Background(), kc, "demo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollbacks) != 1 {
+		t.Fatalf("got %d rollbacks, want 1", len(rollbacks))
+	}
+	for _, r := range RestoreAll(context.Background(), kc, rollbacks, false) {
+		if r.Status != StatusRestored {
+			t.Fatalf("rollback: %s %s", r.Status, r.Message)
+		}
+	}
+
+	d = getWeb(t, kc)
+	res := d.Spec.Template.Spec.Containers[0].Resources
+	if res.Requests.Cpu().String() != "200m" || res.Limits.Cpu().String() != "1" {
+		t.Errorf("回滚后 resources = %v, want requests.cpu 200m limits.cpu 1", res)
+	}
+	for _, key := range []string{AnnotationPreviousResources, AnnotationAppliedAt} {
+		if _, ok := d.Annotations[key]; ok {
+			t.Errorf("回滚后仍有注解 %s", key)
+		}
+	}
+}
+
+func TestApplyExistingRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		recorded   string
+		overwrite  bool
+		wantStatus string
+		wantCPU    string
+		wantErr    string
+	}{
+		{name: "保留已有记录", recorded: `{"containers":{"app":{"requests":{"cpu":"100m"}}}}`, wantStatus: StatusApplied, wantCPU: "100m"},
+		{name: "覆盖已有记录", recorded: `{"containers":{"app":{"requests":{"cpu":"100m"}}}}`, overwrite: true, wantStatus: StatusApplied, wantCPU: "200m"},
+		// 记录中没有的容器以当前值补充
+		{name: "补充未记录的容器", recorded: `{"containers":{"sidecar":{"requests":{"cpu":"50m"}}}}`, wantStatus: StatusApplied, wantCPU: "200m"},
+		{name: "记录格式错误", recorded: "not-json", wantStatus: StatusRejected, wantErr: "--overwrite-previous"},
+		{name: "记录格式错误时覆盖", recorded: "not-json", overwrite: true, wantStatus: StatusApplied, wantCPU: "200m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := testClient(map[string]string{AnnotationPreviousResources: tt.recorded})
+			r := applyCPU(t, kc, 300, tt.overwrite)
+			if r.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", r.Status, r.Message, tt.wantStatus)
+			}
+			d := getWeb(t, kc)
+			if tt.wantErr != "" {
+				if !strings.Contains(r.Message, tt.wantErr) {
+					t.Errorf("message %q 应包含 %q", r.Message, tt.wantErr)
+				}
+				if got := d.Annotations[AnnotationPreviousResources]; got != tt.recorded {
+					t.Errorf("被拒绝后注解 = %q, want %q", got, tt.recorded)
+				}
+				return
+			}
+			if got := recordedCPU(t, d); got != tt.wantCPU {
+				t.Errorf("记录的 requests.cpu = %s, want %s", got, tt.wantCPU)
+			}
+		})
+	}
+}
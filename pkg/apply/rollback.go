@@ -0,0 +1,142 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8stools/pkg/kube"
+	"k8stools/pkg/patch"
+	"k8stools/pkg/workload"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StatusRestored 回滚完成
+const StatusRestored = "✅ 已恢复"
+
+// Rollback 待回滚的工作负载，Target 中 Containers 为记录的修改前资源，Before 为当前资源
+type Rollback struct {
+	Target    patch.Target
+	AppliedAt string
+	// Skipped 记录中有、但 Pod 模板中已不存在的容器
+	Skipped []string
+}
+
+// RollbackResult 单个工作负载的回滚预检或结果
+type RollbackResult struct {
+	Cluster    string `json:"cluster" header:"Cluster"`
+	Namespace  string `json:"namespace" header:"Namespace"`
+	Kind       string `json:"kind" header:"Kind"`
+	Workload   string `json:"workload" header:"Workload"`
+	Containers int    `json:"containers" header:"容器数"`
+	AppliedAt  string `json:"appliedAt" header:"修改时间"`
+	DryRun     bool   `json:"dryRun" header:"DryRun"`
+	Status     string `json:"status" header:"状态"`
+	Reason     string `json:"reason" header:"拒绝原因"`
+	Message    string `json:"message" header:"详情"`
+}
+
+// FindRollbacks 找出命名空间下带 AnnotationPreviousResources 注解的工作负载，selector 为空时不按标签过滤
+// 注解格式错误的工作负载以错误返回，其余工作负载仍然可以回滚
+func FindRollbacks(ctx context.Context, kc kube.Client, ns, selector string) ([]Rollback, error) {
+	workloads, _, err := workload.ListSelected(ctx, kc.Kubernetes(), ns, selector)
+	var errs []error
+	if err != nil {
+		errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+	}
+
+	var rollbacks []Rollback
+	for _, w := range workloads {
+		recorded, ok := w.Annotations[AnnotationPreviousResources]
+		if !ok {
+			continue
+		}
+		var prev Previous
+		if err := json.Unmarshal([]byte(recorded), &prev); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: 注解 %s 格式错误: %w", ns, w.Key(), AnnotationPreviousResources, err))
+			continue
+		}
+		rb := Rollback{
+			Target: patch.Target{
+				Namespace:  ns,
+				Kind:       w.Kind,
+				Name:       w.Name,
+				Containers: make(map[string]corev1.ResourceRequirements),
+				Before:     make(map[string]corev1.ResourceRequirements),
+				Init:       make(map[string]bool),
+				Replace:    true,
+			},
+			AppliedAt: w.Annotations[AnnotationAppliedAt],
+		}
+		restore := func(containers []corev1.Container, recorded map[string]corev1.ResourceRequirements, init bool) {
+			current := make(map[string]corev1.ResourceRequirements, len(containers))
+			for _, c := range containers {
+				current[c.Name] = c.Resources
+			}
+			for name, resources := range recorded {
+				before, ok := current[name]
+				if !ok {
+					rb.Skipped = append(rb.Skipped, name)
+					continue
+				}
+				rb.Target.Containers[name] = resources
+				rb.Target.Before[name] = before
+				rb.Target.Init[name] = init
+			}
+		}
+		restore(w.Template.Spec.Containers, prev.Containers, false)
+		restore(w.Template.Spec.InitContainers, prev.InitContainers, true)
+		sort.Strings(rb.Skipped)
+		rollbacks = append(rollbacks, rb)
+	}
+	return rollbacks, errors.Join(errs...)
+}
+
+// RestoreAll 依次恢复 rollbacks 中的工作负载并删除回滚注解，dryRun 为 true 时只做 server-side dry-run
+func RestoreAll(ctx context.Context, kc kube.Client, rollbacks []Rollback, dryRun bool) []RollbackResult {
+	clientset := kc.Kubernetes()
+	var results []RollbackResult
+	for i := range rollbacks {
+		rb := &rollbacks[i]
+		t := &rb.Target
+		r := RollbackResult{
+			Cluster:    kc.Name(),
+			Namespace:  t.Namespace,
+			Kind:       t.Kind,
+			Workload:   t.Name,
+			Containers: len(t.Containers),
+			AppliedAt:  rb.AppliedAt,
+			DryRun:     dryRun,
+		}
+		if len(rb.Skipped) > 0 {
+			r.Message = fmt.Sprintf("Pod 模板中已没有容器 %v，跳过", rb.Skipped)
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					AnnotationPreviousResources: nil,
+					AnnotationAppliedAt:         nil,
+				},
+			},
+			"spec": t.Spec(),
+		})
+		if err == nil {
+			_, err = Patch(ctx, clientset, t.Namespace, t.Kind, t.Name, data, dryRun)
+		}
+		switch {
+		case err != nil:
+			r.Status = StatusRejected
+			r.Reason = Classify(err)
+			r.Message = err.Error()
+		case dryRun:
+			r.Status = StatusPassed
+		default:
+			r.Status = StatusRestored
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -1,27 +1,160 @@
 package config
 
+// 字段的 desc tag 用于 config schema 生成字段说明
 type Config struct {
-	KubeConfig      string                `json:"kubeconfig"`
-	NameSpace       []string              `json:"namespace"`
-	Prometheus      string                `json:"prometheus"`
-	Cost            Cost                  `json:"cost"`
-	ResourceAdvisor ResourceAdvisorConfig `json:"resourceAdvisor"`
+	KubeConfig        string                `json:"kubeconfig" desc:"kubeconfig 路径，为空时依次尝试 $KUBECONFIG、~/.kube/config、in-cluster"`
+	Context           string                `json:"context" desc:"kubeconfig 中的 context，为空时使用 current-context"`
+	QPS               float32               `json:"qps" desc:"访问 API Server 的 QPS，0 表示使用 client-go 默认值"`
+	Burst             int                   `json:"burst" desc:"访问 API Server 的突发请求数，0 表示使用 client-go 默认值"`
+	Clusters          []Cluster             `json:"clusters" desc:"多集群配置，配合 --context 或 --all-clusters 使用"`
+	NameSpace         []string              `json:"namespace" desc:"需要分析的命名空间，支持 * 和通配符（如 *-prod），配置了 namespaceSelector 时可为空"`
+	NamespaceSelector string                `json:"namespaceSelector" desc:"命名空间标签选择器（如 team=payments），与 namespace 同时配置时取交集"`
+	ExcludeNamespaces []string              `json:"excludeNamespaces" desc:"排除的命名空间，支持通配符（如 kube-*）"`
+	Prometheus        string                `json:"prometheus" desc:"Prometheus 地址，trend、resourceAdvisor 必填"`
+	Cost              Cost                  `json:"cost" desc:"成本估算配置，costEstimator 必填"`
+	ResourceAdvisor   ResourceAdvisorConfig `json:"resourceAdvisor" desc:"资源顾问配置"`
+	Sidecar           SidecarConfig         `json:"sidecar" desc:"cpu 报表中 sidecar 容器的识别规则"`
+	NodePoolLabel     string                `json:"nodePoolLabel" desc:"nodes 报表中区分节点池的节点标签，为空时依次尝试 GKE/EKS/Karpenter/AKS 的节点池标签"`
+	Simulate          SimulateConfig        `json:"simulate" desc:"simulate 装箱模拟配置"`
+	Paradise          ParadiseConfig        `json:"paradise" desc:"paradise 的使用量来源与建议策略"`
+	Recommend         RecommendConfig       `json:"recommend" desc:"paradise、trend、resourceAdvisor 共用的建议策略，可按命名空间或工作负载选择"`
+}
+
+// Cluster 多集群配置中的单个集群
+type Cluster struct {
+	Name       string `json:"name" desc:"报表中 Cluster 列显示的名称"`
+	KubeConfig string `json:"kubeconfig" desc:"kubeconfig 路径，为空时使用顶层 kubeconfig"`
+	Context    string `json:"context" desc:"kubeconfig 中的 context，为空时使用 current-context"`
 }
 
 type Cost struct {
-	CpuPrice int `json:"cpuPrice"`
-	TotalCpu int `json:"totalCpu"`
+	CpuPrice int     `json:"cpuPrice" desc:"单台机器价格（单位元）"`
+	TotalCpu int     `json:"totalCpu" desc:"单台机器 CPU 核数"`
+	MemPrice float64 `json:"memPrice" desc:"每 GiB 内存的价格（单位元），0 表示不计内存成本"`
+	Model    string  `json:"model" desc:"计费口径：request（按 Requests，默认）、usage（按使用量）或 max（取两者较大值）"`
+	Source   string  `json:"source" desc:"使用量来源：metrics（metrics-server，默认）或 prometheus（窗口内平均值，需要配置 prometheus）"`
+	Window   string  `json:"window" desc:"prometheus 来源的平均窗口，如 7d、24h，默认 7d"`
 }
 
+// 成本估算的计费口径
+const (
+	CostModelRequest = "request"
+	CostModelUsage   = "usage"
+	CostModelMax     = "max"
+)
+
+// CostModels 支持的计费口径
+var CostModels = []string{CostModelRequest, CostModelUsage, CostModelMax}
+
+// 成本估算的使用量来源
+const (
+	CostSourceMetrics    = "metrics"
+	CostSourcePrometheus = "prometheus"
+)
+
 // ResourceAdvisorConfig 包含 ResourceAdvisor 所需参数
 type ResourceAdvisorConfig struct {
-	UserMaxConn int64    `json:"userMaxConn"`
-	Business    []string `json:"business"`
+	UserMaxConn int64    `json:"userMaxConn" desc:"单用户最大连接数"`
+	Business    []string `json:"business" desc:"业务线标识"`
 
 	// 可调系数
-	CPURequestFactor    float64 `json:"cpuRequestFactor"`    // request.cpu 系数
-	CPULimitFactor      float64 `json:"cpuLimitFactor"`      // limit.cpu 系数
-	MemRequestFactor    float64 `json:"memRequestFactor"`    // request.mem 系数
-	MemLimitFactor      float64 `json:"memLimitFactor"`      // limit.mem 系数
-	PodRedundancyFactor float64 `json:"podRedundancyFactor"` // pods冗余系数
+	CPURequestFactor    float64 `json:"cpuRequestFactor" desc:"request.cpu 系数"`
+	CPULimitFactor      float64 `json:"cpuLimitFactor" desc:"limit.cpu 系数"`
+	MemRequestFactor    float64 `json:"memRequestFactor" desc:"request.mem 系数"`
+	MemLimitFactor      float64 `json:"memLimitFactor" desc:"limit.mem 系数"`
+	PodRedundancyFactor float64 `json:"podRedundancyFactor" desc:"pods冗余系数"`
+}
+
+// SidecarConfig sidecar 容器识别规则，与内置名称列表合并使用；
+// restartPolicy 为 Always 的 init 容器（原生 sidecar）总是视为 sidecar
+type SidecarConfig struct {
+	Names      []string `json:"names" desc:"sidecar 容器名称，追加到内置列表（istio-proxy、linkerd-proxy、envoy、fluent-bit 等）"`
+	Patterns   []string `json:"patterns" desc:"sidecar 容器名称的正则表达式，如 ^.*-exporter$"`
+	Annotation string   `json:"annotation" desc:"Pod 注解名，注解值为逗号分隔的 sidecar 容器名称，为空时使用 k8stools.io/sidecars"`
+}
+
+// SimulateConfig simulate 装箱模拟的节点规格，nodeCpu 与 nodeMemory 均为 0 时使用集群中现有节点
+type SimulateConfig struct {
+	NodeCPU    float64 `json:"nodeCpu" desc:"模拟节点的可分配 CPU 核数"`
+	NodeMemory float64 `json:"nodeMemory" desc:"模拟节点的可分配内存（GiB）"`
+	NodePods   int     `json:"nodePods" desc:"模拟节点的最大 Pod 数"`
+}
+
+// paradise 的使用量来源
+const (
+	ParadiseSourceMetrics    = "metrics"
+	ParadiseSourcePrometheus = "prometheus"
+)
+
+// ParadiseConfig paradise 的使用量来源与建议策略
+type ParadiseConfig struct {
+	Source string         `json:"source" desc:"使用量来源：metrics（metrics-server，默认）或 prometheus（历史数据，需要配置 prometheus）"`
+	Window string         `json:"window" desc:"prometheus 来源的历史窗口，如 7d、24h，默认 7d"`
+	Policy ParadisePolicy `json:"policy" desc:"建议策略，数值为 0 时使用默认值"`
+}
+
+// ParadisePolicy paradise 的建议策略：Requests/Limits 分别以所选分位数（0 表示平均值）为基准，
+// 低于 low* 阈值时使用最小推荐值，否则乘以对应系数，Limits 再加上 headroom 余量
+type ParadisePolicy struct {
+	CPURequestPercentile float64 `json:"cpuRequestPercentile" desc:"计算 CPU Requests 使用的分位数（0-100），0 表示平均值"`
+	CPULimitPercentile   float64 `json:"cpuLimitPercentile" desc:"计算 CPU Limits 使用的分位数（0-100），0 表示平均值"`
+	MemRequestPercentile float64 `json:"memRequestPercentile" desc:"计算内存 Requests 使用的分位数（0-100），0 表示平均值"`
+	MemLimitPercentile   float64 `json:"memLimitPercentile" desc:"计算内存 Limits 使用的分位数（0-100），0 表示平均值"`
+	CPUHeadroom          float64 `json:"cpuHeadroom" desc:"CPU Limits 的额外余量比例，如 0.2 表示再加 20%"`
+	MemHeadroom          float64 `json:"memHeadroom" desc:"内存 Limits 的额外余量比例，如 0.2 表示再加 20%"`
+
+	LowCPU           int64   `json:"lowCpu" desc:"CPU 基准低于该值（m）时使用最小推荐值"`
+	HighCPU          int64   `json:"highCpu" desc:"CPU 基准高于该值（m）时视为高负载"`
+	MinCPURequest    int64   `json:"minCpuRequest" desc:"最小 CPU Requests（m）"`
+	MinCPULimit      int64   `json:"minCpuLimit" desc:"最小 CPU Limits（m）"`
+	CPURequestFactor float64 `json:"cpuRequestFactor" desc:"CPU Requests 系数"`
+	CPULimitFactor   float64 `json:"cpuLimitFactor" desc:"CPU Limits 系数"`
+
+	LowMem               int64   `json:"lowMem" desc:"内存基准低于该值（Mi）时使用最小推荐值"`
+	HighMem              int64   `json:"highMem" desc:"内存基准高于该值（Mi）时使用高负载系数"`
+	MinMemRequest        int64   `json:"minMemRequest" desc:"最小内存 Requests（Mi）"`
+	MinMemLimit          int64   `json:"minMemLimit" desc:"最小内存 Limits（Mi）"`
+	MemRequestFactor     float64 `json:"memRequestFactor" desc:"内存 Requests 系数"`
+	HighMemRequestFactor float64 `json:"highMemRequestFactor" desc:"高负载时的内存 Requests 系数"`
+	MemLimitFactor       float64 `json:"memLimitFactor" desc:"内存 Limits 系数"`
+}
+
+// 内置的建议策略名称
+const (
+	PolicyConservative = "conservative"
+	PolicyBalanced     = "balanced"
+	PolicyAggressive   = "aggressive"
+)
+
+// BuiltinPolicies 内置策略名称，自定义策略不能与其重名
+var BuiltinPolicies = []string{PolicyConservative, PolicyBalanced, PolicyAggressive}
+
+// PolicyVariables 策略表达式可使用的变量：CPU 表达式中为 CPU 使用量（m），内存表达式中为内存使用量（Mi）
+var PolicyVariables = []string{"min", "avg", "p50", "p90", "p95", "p99", "max"}
+
+// RecommendConfig 建议策略的选择：rules 按顺序匹配第一条，未匹配时使用 policy，
+// policy 为空时各命令沿用自身的内置算法
+type RecommendConfig struct {
+	Policy   string            `json:"policy" desc:"默认策略：conservative、balanced、aggressive 或 policies 中的自定义策略，为空时各命令沿用内置算法"`
+	Policies []RecommendPolicy `json:"policies" desc:"自定义策略，表达式可使用 min、avg、p50、p90、p95、p99、max 变量以及 max()、min()、ceil() 函数"`
+	Rules    []RecommendRule   `json:"rules" desc:"按命名空间、工作负载选择策略，按顺序匹配第一条"`
+	// MinCPU、MinMemory 策略建议值的下限，表达式结果低于下限（或无法计算）时取下限，并在计算依据中注明
+	MinCPU    int64 `json:"minCpu" desc:"策略建议 CPU Requests/Limits 的下限（m）"`
+	MinMemory int64 `json:"minMemory" desc:"策略建议内存 Requests/Limits 的下限（Mi）"`
+}
+
+// RecommendPolicy 以表达式定义的自定义策略，如 cpuRequest: "p90 * 1.1"
+type RecommendPolicy struct {
+	Name       string `json:"name" desc:"策略名称，供 policy 与 rules 引用"`
+	CPURequest string `json:"cpuRequest" desc:"CPU Requests（m）表达式"`
+	CPULimit   string `json:"cpuLimit" desc:"CPU Limits（m）表达式"`
+	MemRequest string `json:"memRequest" desc:"内存 Requests（Mi）表达式"`
+	MemLimit   string `json:"memLimit" desc:"内存 Limits（Mi）表达式"`
+}
+
+// RecommendRule 按命名空间、工作负载选择策略的规则
+type RecommendRule struct {
+	Namespace string `json:"namespace" desc:"命名空间通配符，为空匹配全部"`
+	Workload  string `json:"workload" desc:"工作负载名称通配符（resourceAdvisor 中为服务名），为空匹配全部"`
+	Policy    string `json:"policy" desc:"使用的策略名称"`
 }
@@ -0,0 +1,133 @@
+package config
+
+// 资源顾问系数的默认值，与 resourceAdvisor 中系数为 0 时的取值保持一致
+const (
+	DefaultCPURequestFactor    = 1.0
+	DefaultCPULimitFactor      = 2.0
+	DefaultMemRequestFactor    = 1.0
+	DefaultMemLimitFactor      = 2.0
+	DefaultPodRedundancyFactor = 1.5
+)
+
+// 成本估算的默认机器规格，仅用于生成配置模板
+const (
+	DefaultCpuPrice = 4000
+	DefaultTotalCpu = 16
+)
+
+// 成本估算的默认计费口径与使用量来源
+const (
+	DefaultCostModel  = CostModelRequest
+	DefaultCostSource = CostSourceMetrics
+	DefaultCostWindow = "7d"
+)
+
+// paradise 的默认来源与策略，与早期版本固定规则一致
+const (
+	DefaultParadiseSource         = ParadiseSourceMetrics
+	DefaultParadiseWindow         = "7d"
+	DefaultLowCPU                 = 50
+	DefaultHighCPU                = 1000
+	DefaultMinCPURequest          = 50
+	DefaultMinCPULimit            = 100
+	DefaultParadiseCPURequest     = 0.5
+	DefaultParadiseCPULimit       = 1.0
+	DefaultLowMem                 = 64
+	DefaultHighMem                = 1024
+	DefaultMinMemRequest          = 64
+	DefaultMinMemLimit            = 128
+	DefaultParadiseMemRequest     = 0.8
+	DefaultParadiseHighMemRequest = 0.75
+	DefaultParadiseMemLimit       = 1.5
+)
+
+// recommend 策略建议值的默认下限：10m 的 CPU 与 32Mi 的内存，避免使用量接近 0 时给出无法运行的 Requests/Limits
+const (
+	DefaultRecommendMinCPU    = 10
+	DefaultRecommendMinMemory = 32
+)
+
+// DefaultNodePods simulate 模拟节点的默认最大 Pod 数，与 kubelet 的 maxPods 默认值一致
+const DefaultNodePods = 110
+
+// Default 返回带默认值的配置，config init 以此为模板
+func Default() *Config {
+	c := &Config{
+		Cost: Cost{CpuPrice: DefaultCpuPrice, TotalCpu: DefaultTotalCpu},
+	}
+	ApplyDefaults(c)
+	return c
+}
+
+// ApplyDefaults 为未填写（为 0 或空）的系数、模拟节点 Pod 数、成本计费口径、paradise 策略与 recommend 下限填充默认值，得到各模块实际生效的配置
+func ApplyDefaults(c *Config) {
+	ra := &c.ResourceAdvisor
+	if ra.CPURequestFactor == 0 {
+		ra.CPURequestFactor = DefaultCPURequestFactor
+	}
+	if ra.CPULimitFactor == 0 {
+		ra.CPULimitFactor = DefaultCPULimitFactor
+	}
+	if ra.MemRequestFactor == 0 {
+		ra.MemRequestFactor = DefaultMemRequestFactor
+	}
+	if ra.MemLimitFactor == 0 {
+		ra.MemLimitFactor = DefaultMemLimitFactor
+	}
+	if ra.PodRedundancyFactor == 0 {
+		ra.PodRedundancyFactor = DefaultPodRedundancyFactor
+	}
+	if c.Simulate.NodePods == 0 {
+		c.Simulate.NodePods = DefaultNodePods
+	}
+
+	cost := &c.Cost
+	if cost.Model == "" {
+		cost.Model = DefaultCostModel
+	}
+	if cost.Source == "" {
+		cost.Source = DefaultCostSource
+	}
+	if cost.Window == "" {
+		cost.Window = DefaultCostWindow
+	}
+
+	if c.Recommend.MinCPU == 0 {
+		c.Recommend.MinCPU = DefaultRecommendMinCPU
+	}
+	if c.Recommend.MinMemory == 0 {
+		c.Recommend.MinMemory = DefaultRecommendMinMemory
+	}
+
+	pd := &c.Paradise
+	if pd.Source == "" {
+		pd.Source = DefaultParadiseSource
+	}
+	if pd.Window == "" {
+		pd.Window = DefaultParadiseWindow
+	}
+	p := &pd.Policy
+	setInt := func(v *int64, d int64) {
+		if *v == 0 {
+			*v = d
+		}
+	}
+	setFloat := func(v *float64, d float64) {
+		if *v == 0 {
+			*v = d
+		}
+	}
+	setInt(&p.LowCPU, DefaultLowCPU)
+	setInt(&p.HighCPU, DefaultHighCPU)
+	setInt(&p.MinCPURequest, DefaultMinCPURequest)
+	setInt(&p.MinCPULimit, DefaultMinCPULimit)
+	setFloat(&p.CPURequestFactor, DefaultParadiseCPURequest)
+	setFloat(&p.CPULimitFactor, DefaultParadiseCPULimit)
+	setInt(&p.LowMem, DefaultLowMem)
+	setInt(&p.HighMem, DefaultHighMem)
+	setInt(&p.MinMemRequest, DefaultMinMemRequest)
+	setInt(&p.MinMemLimit, DefaultMinMemLimit)
+	setFloat(&p.MemRequestFactor, DefaultParadiseMemRequest)
+	setFloat(&p.HighMemRequestFactor, DefaultParadiseHighMemRequest)
+	setFloat(&p.MemLimitFactor, DefaultParadiseMemLimit)
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaURI JSON Schema 规范版本，draft-07 被主流编辑器的 YAML 插件支持
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// Schema 根据 Config 结构体生成 JSON Schema
+// 字段说明取自 desc tag，默认值取自 ApplyDefaults；未声明的字段不允许出现，便于编辑器提示拼写错误
+func Schema() map[string]interface{} {
+	defaults := &Config{}
+	ApplyDefaults(defaults)
+
+	s := schemaFor(reflect.TypeOf(Config{}), reflect.ValueOf(*defaults))
+	s["$schema"] = SchemaURI
+	s["title"] = "k8stools 配置文件"
+	return s
+}
+
+func schemaFor(t reflect.Type, defaults reflect.Value) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			var dv reflect.Value
+			if defaults.IsValid() {
+				dv = defaults.Field(i)
+			}
+			p := schemaFor(f.Type, dv)
+			if desc := f.Tag.Get("desc"); desc != "" {
+				p["description"] = desc
+			}
+			props[name] = p
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem(), reflect.Value{}),
+		}
+	}
+
+	s := map[string]interface{}{}
+	switch t.Kind() {
+	case reflect.String:
+		s["type"] = "string"
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s["type"] = "integer"
+		s["minimum"] = 0
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+		s["minimum"] = 0
+	}
+	if defaults.IsValid() && !defaults.IsZero() {
+		s["default"] = defaults.Interface()
+	}
+	return s
+}
+
+// jsonName 返回字段在配置文件中的名称，忽略的字段返回空
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
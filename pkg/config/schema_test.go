@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	// 与 config schema 命令一样经过 JSON 编码，检查输出结构
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root["$schema"] != SchemaURI || root["type"] != "object" || root["additionalProperties"] != false {
+		t.Errorf("根节点 = %v", root)
+	}
+
+	// lookup 按 properties 逐级查找字段
+	lookup := func(path ...string) map[string]interface{} {
+		t.Helper()
+		node := root
+		for _, name := range path {
+			props, _ := node["properties"].(map[string]interface{})
+			next, ok := props[name].(map[string]interface{})
+			if !ok {
+				t.Fatalf("schema 中没有 %v", path)
+			}
+			node = next
+		}
+		return node
+	}
+
+	tests := []struct {
+		path []string
+		want map[string]interface{}
+	}{
+		{
+			path: []string{"prometheus"},
+			want: map[string]interface{}{"type": "string", "description": "Prometheus 地址，trend、resourceAdvisor 必填"},
+		},
+		{
+			path: []string{"namespace"},
+			want: map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"type": "string"},
+				"description": "需要分析的命名空间，支持 * 和通配符（如 *-prod），配置了 namespaceSelector 时可为空",
+			},
+		},
+		// 默认值取自 ApplyDefaults
+		{
+			path: []string{"resourceAdvisor", "podRedundancyFactor"},
+			want: map[string]interface{}{"type": "number", "minimum": 0.0, "default": DefaultPodRedundancyFactor, "description": "pods冗余系数"},
+		},
+		{
+			path: []string{"cost", "model"},
+			want: map[string]interface{}{"type": "string", "default": DefaultCostModel, "description": "计费口径：request（按 Requests，默认）、usage（按使用量）或 max（取两者较大值）"},
+		},
+		// 没有默认值的字段不输出 default
+		{
+			path: []string{"cost", "cpuPrice"},
+			want: map[string]interface{}{"type": "integer", "minimum": 0.0, "description": "单台机器价格（单位元）"},
+		},
+	}
+	for _, tt := range tests {
+		if got := lookup(tt.path...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range [][]string{{"cost"}, {"resourceAdvisor"}, {"paradise", "policy"}} {
+		if node := lookup(path...); node["type"] != "object" || node["additionalProperties"] != false {
+			t.Errorf("%v 应为不允许额外字段的 object: %v", path, node)
+		}
+	}
+	clusters := lookup("clusters")
+	items, _ := clusters["items"].(map[string]interface{})
+	if items["type"] != "object" || items["additionalProperties"] != false {
+		t.Errorf("clusters.items = %v, want object", items)
+	}
+}
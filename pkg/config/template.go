@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/template"
+)
+
+// configTemplate 带完整注释的配置文件模板，字段与 Config 一一对应
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# k8stools 配置文件，由 k8stools config init 生成
+# 校验：k8stools config validate [子命令...] -f <本文件>
+
+kubeconfig: {{ quote .KubeConfig }}   # 为空时依次尝试 $KUBECONFIG、~/.kube/config、in-cluster
+context: {{ quote .Context }}      # kubeconfig 中的 context，为空使用 current-context
+qps: {{ .QPS }}           # 访问 API Server 的 QPS，0 使用默认值
+burst: {{ .Burst }}         # 访问 API Server 的突发请求数，0 使用默认值
+# 多集群：配合 --context <name> 或 --all-clusters 使用，报表会增加 Cluster 列
+{{- if .Clusters }}
+clusters:
+{{- range .Clusters }}
+  - name: {{ quote .Name }}
+    kubeconfig: {{ quote .KubeConfig }}
+    context: {{ quote .Context }}
+{{- end }}
+{{- else }}
+# clusters:
+#   - name: prod-bj
+#     kubeconfig: /root/.kube/prod-bj
+#   - name: prod-sh
+#     context: prod-sh-admin   # kubeconfig 为空时沿用顶层 kubeconfig
+{{- end }}
+
+# 需要分析的命名空间，支持 *、通配符（如 *-prod）
+namespace:
+{{- range .NameSpace }}
+  - {{ quote . }}
+{{- end }}
+# 命名空间标签选择器（如 team=payments），与 namespace 同时配置时取交集
+namespaceSelector: {{ quote .NamespaceSelector }}
+# 排除的命名空间，支持通配符（如 kube-*）
+excludeNamespaces:{{ if not .ExcludeNamespaces }} []{{ end }}
+{{- range .ExcludeNamespaces }}
+  - {{ quote . }}
+{{- end }}
+
+# Prometheus 地址，trend、resourceAdvisor 必填
+prometheus: {{ quote .Prometheus }}
+
+# 成本估算配置，costEstimator 必填
+cost:
+  cpuPrice: {{ .Cost.CpuPrice }}   # 单台机器价格（单位元）
+  totalCpu: {{ .Cost.TotalCpu }}     # 单台机器 CPU 核数
+  memPrice: {{ .Cost.MemPrice }}        # 每 GiB 内存价格（单位元），0 表示不计内存成本
+  model: {{ quote .Cost.Model }}   # request（按 Requests）、usage（按使用量）或 max（取两者较大值）
+  source: {{ quote .Cost.Source }}  # 使用量来源：metrics（metrics-server）或 prometheus（窗口内平均值）
+  window: {{ quote .Cost.Window }}       # prometheus 来源的平均窗口（7d）
+
+# 资源顾问配置（resourceAdvisor），系数为 0 时使用括号中的默认值
+resourceAdvisor:
+  userMaxConn: {{ .ResourceAdvisor.UserMaxConn }}          # 单用户最大连接数
+  business:{{ if not .ResourceAdvisor.Business }} []{{ end }}                # 业务线标识
+{{- range .ResourceAdvisor.Business }}
+    - {{ quote . }}
+{{- end }}
+  cpuRequestFactor: {{ printf "%.1f" .ResourceAdvisor.CPURequestFactor }}     # request.cpu 系数（1.0）
+  cpuLimitFactor: {{ printf "%.1f" .ResourceAdvisor.CPULimitFactor }}       # limit.cpu 系数（2.0）
+  memRequestFactor: {{ printf "%.1f" .ResourceAdvisor.MemRequestFactor }}     # request.mem 系数（1.0）
+  memLimitFactor: {{ printf "%.1f" .ResourceAdvisor.MemLimitFactor }}       # limit.mem 系数（2.0）
+  podRedundancyFactor: {{ printf "%.1f" .ResourceAdvisor.PodRedundancyFactor }}  # 副本冗余系数（1.5）
+
+# cpu 报表的 sidecar 识别规则，名称追加到内置列表（istio-proxy、linkerd-proxy、envoy、fluent-bit 等）；
+# restartPolicy 为 Always 的 init 容器（原生 sidecar）总是视为 sidecar
+sidecar:
+  names:{{ if not .Sidecar.Names }} []{{ end }}
+{{- range .Sidecar.Names }}
+    - {{ quote . }}
+{{- end }}
+  patterns:{{ if not .Sidecar.Patterns }} []{{ end }}            # 容器名正则，如 ^.*-exporter$
+{{- range .Sidecar.Patterns }}
+    - {{ quote . }}
+{{- end }}
+  annotation: {{ quote .Sidecar.Annotation }}        # Pod 注解名，值为逗号分隔的容器名，为空使用 k8stools.io/sidecars
+
+# nodes 报表中区分节点池的节点标签，为空时依次尝试 GKE/EKS/Karpenter/AKS 的节点池标签
+nodePoolLabel: {{ quote .NodePoolLabel }}
+
+# simulate 装箱模拟的节点规格，nodeCpu 与 nodeMemory 均为 0 时使用集群中现有节点
+simulate:
+  nodeCpu: {{ .Simulate.NodeCPU }}        # 可分配 CPU 核数
+  nodeMemory: {{ .Simulate.NodeMemory }}     # 可分配内存（GiB）
+  nodePods: {{ .Simulate.NodePods }}     # 最大 Pod 数（110）
+
+# paradise 的使用量来源与建议策略，数值为 0 时使用括号中的默认值
+paradise:
+  source: {{ quote .Paradise.Source }}   # metrics（metrics-server）或 prometheus（历史数据）
+  window: {{ quote .Paradise.Window }}         # prometheus 来源的历史窗口（7d）
+  policy:
+    # 分位数（0-100），0 表示使用平均值；如 Requests 取 p90、Limits 取 p99
+    cpuRequestPercentile: {{ .Paradise.Policy.CPURequestPercentile }}
+    cpuLimitPercentile: {{ .Paradise.Policy.CPULimitPercentile }}
+    memRequestPercentile: {{ .Paradise.Policy.MemRequestPercentile }}
+    memLimitPercentile: {{ .Paradise.Policy.MemLimitPercentile }}
+    cpuHeadroom: {{ .Paradise.Policy.CPUHeadroom }}            # Limits 额外余量，0.2 表示再加 20%
+    memHeadroom: {{ .Paradise.Policy.MemHeadroom }}
+    lowCpu: {{ .Paradise.Policy.LowCPU }}                # 低于该值（m）使用最小推荐值（50）
+    highCpu: {{ .Paradise.Policy.HighCPU }}             # 高于该值（m）视为高负载（1000）
+    minCpuRequest: {{ .Paradise.Policy.MinCPURequest }}         # （50）
+    minCpuLimit: {{ .Paradise.Policy.MinCPULimit }}          # （100）
+    cpuRequestFactor: {{ printf "%.2f" .Paradise.Policy.CPURequestFactor }}    # （0.50）
+    cpuLimitFactor: {{ printf "%.2f" .Paradise.Policy.CPULimitFactor }}      # （1.00）
+    lowMem: {{ .Paradise.Policy.LowMem }}                # 低于该值（Mi）使用最小推荐值（64）
+    highMem: {{ .Paradise.Policy.HighMem }}             # 高于该值（Mi）使用 highMemRequestFactor（1024）
+    minMemRequest: {{ .Paradise.Policy.MinMemRequest }}         # （64）
+    minMemLimit: {{ .Paradise.Policy.MinMemLimit }}          # （128）
+    memRequestFactor: {{ printf "%.2f" .Paradise.Policy.MemRequestFactor }}    # （0.80）
+    highMemRequestFactor: {{ printf "%.2f" .Paradise.Policy.HighMemRequestFactor }}  # （0.75）
+    memLimitFactor: {{ printf "%.2f" .Paradise.Policy.MemLimitFactor }}      # （1.50）
+
+# paradise、trend、resourceAdvisor 共用的建议策略：rules 按顺序匹配第一条，未匹配时使用 policy；
+# policy 为空时各命令沿用内置算法。内置策略：conservative、balanced、aggressive
+recommend:
+  policy: {{ quote .Recommend.Policy }}
+  minCpu: {{ .Recommend.MinCPU }}          # 建议值下限（m），表达式结果低于下限时取下限（10）
+  minMemory: {{ .Recommend.MinMemory }}       # 建议值下限（Mi）（32）
+  # 自定义策略，表达式可使用 min、avg、p50、p90、p95、p99、max 及 max()、min()、ceil()；
+  # CPU 单位为 m，内存单位为 Mi
+{{- if .Recommend.Policies }}
+  policies:
+{{- range .Recommend.Policies }}
+    - name: {{ quote .Name }}
+      cpuRequest: {{ quote .CPURequest }}
+      cpuLimit: {{ quote .CPULimit }}
+      memRequest: {{ quote .MemRequest }}
+      memLimit: {{ quote .MemLimit }}
+{{- end }}
+{{- else }}
+  # policies:
+  #   - name: steady
+  #     cpuRequest: "p90 * 1.1"
+  #     cpuLimit: "max(p99 * 1.5, 100)"
+  #     memRequest: "p95 * 1.1"
+  #     memLimit: "max * 1.3"
+{{- end }}
+{{- if .Recommend.Rules }}
+  rules:
+{{- range .Recommend.Rules }}
+    - namespace: {{ quote .Namespace }}
+      workload: {{ quote .Workload }}
+      policy: {{ quote .Policy }}
+{{- end }}
+{{- else }}
+  # rules:
+  #   - namespace: "*-prod"
+  #     policy: conservative
+  #   - namespace: batch
+  #     workload: "report-*"
+  #     policy: aggressive
+{{- end }}
+`))
+
+// WriteTemplate 将配置按带注释的模板写出，生成的文件可直接被 ReadYaml 读取
+func WriteTemplate(w io.Writer, c *Config) error {
+	if err := configTemplate.Execute(w, c); err != nil {
+		return fmt.Errorf("生成配置文件失败: %w", err)
+	}
+	return nil
+}
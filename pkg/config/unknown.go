@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// UnknownKeys 检查配置文件中 Config 未声明的字段，返回带拼写建议的字段错误
+// 字段名与 viper 一致按大小写不敏感匹配
+func UnknownKeys(path string) ([]FieldError, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件报错: %w", err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析配置文件时出错: %w", err)
+	}
+	return unknownKeys(raw, reflect.TypeOf(Config{}), ""), nil
+}
+
+func unknownKeys(raw interface{}, t reflect.Type, prefix string) []FieldError {
+	switch t.Kind() {
+	case reflect.Slice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil
+		}
+		var errs []FieldError
+		for i, item := range items {
+			errs = append(errs, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", prefix, i))...)
+		}
+		return errs
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	fields := make(map[string]reflect.StructField)
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields[strings.ToLower(name)] = t.Field(i)
+			names = append(names, name)
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []FieldError
+	for _, k := range keys {
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+		f, ok := fields[strings.ToLower(k)]
+		if !ok {
+			msg := "未知字段，将被忽略"
+			if s := suggest(k, names); s != "" {
+				msg += fmt.Sprintf("，是否为 %s？", s)
+			}
+			errs = append(errs, FieldError{Field: field, Message: msg})
+			continue
+		}
+		errs = append(errs, unknownKeys(m[k], f.Type, field)...)
+	}
+	return errs
+}
+
+// suggest 返回编辑距离最近且足够接近的候选字段
+func suggest(key string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist < 0 || bestDist > len(best)/3+1 {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
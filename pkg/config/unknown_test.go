@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownKeys(t *testing.T) {
+	path := writeConfig(t, `
+NameSpace: [demo]
+prometheous: http://p:9090
+foo: bar
+cost:
+  cpuPrise: 4000
+resourceAdvisor:
+  podRedundencyFactor: 1.5
+clusters:
+  - name: a
+  - name: b
+    contxt: prod
+recommend:
+  rules:
+    - namespace: "*"
+      policy: p95
+      polcy: p95
+`)
+	got, err := UnknownKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按字段名排序输出；大小写不同的字段与 viper 一致视为已知字段
+	want := []FieldError{
+		{Field: "clusters[1].contxt", Message: "未知字段，将被忽略，是否为 context？"},
+		{Field: "cost.cpuPrise", Message: "未知字段，将被忽略，是否为 cpuPrice？"},
+		{Field: "foo", Message: "未知字段，将被忽略"},
+		{Field: "prometheous", Message: "未知字段，将被忽略，是否为 prometheus？"},
+		{Field: "recommend.rules[0].polcy", Message: "未知字段，将被忽略，是否为 policy？"},
+		{Field: "resourceAdvisor.podRedundencyFactor", Message: "未知字段，将被忽略，是否为 podRedundancyFactor？"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownKeys() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"namespace", "namespaceSelector", "qps", "prometheus"}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"namespce", "namespace"},
+		{"NAMESPACE", "namespace"},
+		{"namespaceSelecter", "namespaceSelector"},
+		// 允许的编辑距离为 len/3+1，短字段只容忍很小的差异
+		{"qsp", "qps"},
+		{"burst", ""},
+		{"promql", ""},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.key, candidates); got != tt.want {
+			t.Errorf("suggest(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := suggest("foo", nil); got != "" {
+		t.Errorf("suggest without candidates = %q, want empty", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"cpuPrice", "cpuPrise", 1},
+		{"qps", "qsp", 2},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	path := writeConfig(t, "namespace: [demo]\nprometheous: http://p:9090\n")
+	_, err := Load(LoadOptions{Path: path, Strict: true})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Errors) != 1 || ve.Errors[0].Field != "prometheous" {
+		t.Errorf("Load(Strict) error = %v, want prometheous 未知字段", err)
+	}
+	if _, err := Load(LoadOptions{Path: path}); err != nil {
+		t.Errorf("非 Strict 时未知字段只警告: %v", err)
+	}
+}
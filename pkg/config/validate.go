@@ -0,0 +1,307 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8stools/pkg/expr"
+)
+
+// FieldError 单个字段的校验错误，Field 为配置文件中的字段路径（如 cost.cpuPrice）
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError 汇总一次校验发现的全部问题
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败，共 %d 个问题:", len(e.Errors))
+	for _, fe := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
+
+// 需要额外必填字段的子命令
+var (
+	prometheusCommands = map[string]bool{"trend": true, "resourceAdvisor": true}
+	costCommands       = map[string]bool{"costEstimator": true}
+	// 使用 paradise 建议的子命令，paradise.source 为 prometheus 时同样需要 Prometheus 地址
+	paradiseCommands = map[string]bool{"paradise": true, "simulate": true, "apply-recommendations": true}
+	// 集群级子命令不读取 namespace 配置
+	clusterCommands = map[string]bool{"nodes": true}
+)
+
+// Validate 校验配置，commands 为将要执行的子命令，用于检查各子命令的必填字段；
+// 通用规则与已填写字段的格式总会检查。所有问题一次性以 *ValidationError 返回
+func Validate(c *Config, commands ...string) error {
+	if c == nil {
+		return &ValidationError{Errors: []FieldError{{Field: "(root)", Message: "配置为空"}}}
+	}
+
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// 通用规则，只执行集群级子命令时 namespace 可为空
+	needNamespace := len(commands) == 0
+	for _, cmd := range commands {
+		if !clusterCommands[cmd] {
+			needNamespace = true
+		}
+	}
+	if needNamespace && len(c.NameSpace) == 0 && c.NamespaceSelector == "" {
+		add("namespace", "至少需要配置一个命名空间或 namespaceSelector")
+	}
+	for i, ns := range c.NameSpace {
+		if strings.TrimSpace(ns) == "" {
+			add(fmt.Sprintf("namespace[%d]", i), "命名空间不能为空")
+		} else if _, err := path.Match(ns, ""); err != nil {
+			add(fmt.Sprintf("namespace[%d]", i), "通配符格式错误: %q", ns)
+		}
+	}
+	if c.NamespaceSelector != "" {
+		if _, err := labels.Parse(c.NamespaceSelector); err != nil {
+			add("namespaceSelector", "标签选择器格式错误: %v", err)
+		}
+	}
+	for i, ns := range c.ExcludeNamespaces {
+		if _, err := path.Match(ns, ""); err != nil {
+			add(fmt.Sprintf("excludeNamespaces[%d]", i), "通配符格式错误: %q", ns)
+		}
+	}
+	if c.QPS < 0 {
+		add("qps", "不能为负数")
+	}
+	if c.Burst < 0 {
+		add("burst", "不能为负数")
+	}
+	names := make(map[string]bool)
+	for i, cl := range c.Clusters {
+		field := fmt.Sprintf("clusters[%d]", i)
+		if cl.Name == "" && cl.Context == "" {
+			add(field+".name", "name 和 context 至少填写一个")
+		}
+		if cl.Name != "" {
+			if names[cl.Name] {
+				add(field+".name", "集群名称 %q 重复", cl.Name)
+			}
+			names[cl.Name] = true
+		}
+	}
+
+	// 已填写字段的格式
+	if c.Prometheus != "" {
+		if u, err := url.Parse(c.Prometheus); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("prometheus", "不是合法的 http(s) 地址: %q", c.Prometheus)
+		}
+	}
+	if c.Cost.CpuPrice < 0 {
+		add("cost.cpuPrice", "不能为负数")
+	}
+	if c.Cost.TotalCpu < 0 {
+		add("cost.totalCpu", "不能为负数")
+	}
+	validateCost(&c.Cost, add)
+	for i, p := range c.Sidecar.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			add(fmt.Sprintf("sidecar.patterns[%d]", i), "正则表达式格式错误: %v", err)
+		}
+	}
+	if c.Simulate.NodeCPU < 0 {
+		add("simulate.nodeCpu", "不能为负数")
+	}
+	if c.Simulate.NodeMemory < 0 {
+		add("simulate.nodeMemory", "不能为负数")
+	}
+	if c.Simulate.NodePods < 0 {
+		add("simulate.nodePods", "不能为负数")
+	}
+	if (c.Simulate.NodeCPU > 0) != (c.Simulate.NodeMemory > 0) {
+		add("simulate", "nodeCpu 与 nodeMemory 需同时配置")
+	}
+	validateParadise(&c.Paradise, add)
+	validateRecommend(&c.Recommend, add)
+	factors := []struct {
+		field string
+		value float64
+	}{
+		{"resourceAdvisor.cpuRequestFactor", c.ResourceAdvisor.CPURequestFactor},
+		{"resourceAdvisor.cpuLimitFactor", c.ResourceAdvisor.CPULimitFactor},
+		{"resourceAdvisor.memRequestFactor", c.ResourceAdvisor.MemRequestFactor},
+		{"resourceAdvisor.memLimitFactor", c.ResourceAdvisor.MemLimitFactor},
+		{"resourceAdvisor.podRedundancyFactor", c.ResourceAdvisor.PodRedundancyFactor},
+	}
+	for _, f := range factors {
+		if f.value < 0 {
+			add(f.field, "系数不能为负数")
+		}
+	}
+
+	// 子命令必填字段
+	for _, cmd := range commands {
+		if prometheusCommands[cmd] && c.Prometheus == "" {
+			add("prometheus", "%s 需要配置 Prometheus 地址", cmd)
+		}
+		if paradiseCommands[cmd] && c.Paradise.Source == ParadiseSourcePrometheus && c.Prometheus == "" {
+			add("prometheus", "paradise.source 为 prometheus 时 %s 需要配置 Prometheus 地址", cmd)
+		}
+		if costCommands[cmd] {
+			if c.Cost.Source == CostSourcePrometheus && c.Prometheus == "" {
+				add("prometheus", "cost.source 为 prometheus 时 %s 需要配置 Prometheus 地址", cmd)
+			}
+			if c.Cost.CpuPrice <= 0 {
+				add("cost.cpuPrice", "%s 需要配置大于 0 的机器价格", cmd)
+			}
+			if c.Cost.TotalCpu <= 0 {
+				add("cost.totalCpu", "%s 需要配置大于 0 的机器 CPU 核数", cmd)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateCost(cost *Cost, add func(field, format string, args ...interface{})) {
+	if cost.MemPrice < 0 {
+		add("cost.memPrice", "不能为负数")
+	}
+	if cost.Model != "" && !slices.Contains(CostModels, cost.Model) {
+		add("cost.model", "不支持的计费口径 %q（可选 %s）", cost.Model, strings.Join(CostModels, "、"))
+	}
+	if cost.Source != "" && cost.Source != CostSourceMetrics && cost.Source != CostSourcePrometheus {
+		add("cost.source", "不支持的来源 %q（可选 %s、%s）", cost.Source, CostSourceMetrics, CostSourcePrometheus)
+	}
+	if cost.Window != "" {
+		if d, err := model.ParseDuration(cost.Window); err != nil || d <= 0 {
+			add("cost.window", "时间窗口格式错误: %q（如 7d、24h）", cost.Window)
+		}
+	}
+}
+
+func validateParadise(pd *ParadiseConfig, add func(field, format string, args ...interface{})) {
+	if pd.Source != "" && pd.Source != ParadiseSourceMetrics && pd.Source != ParadiseSourcePrometheus {
+		add("paradise.source", "不支持的来源 %q（可选 %s、%s）", pd.Source, ParadiseSourceMetrics, ParadiseSourcePrometheus)
+	}
+	if pd.Window != "" {
+		if d, err := model.ParseDuration(pd.Window); err != nil || d <= 0 {
+			add("paradise.window", "时间窗口格式错误: %q（如 7d、24h）", pd.Window)
+		}
+	}
+
+	p := &pd.Policy
+	percentiles := []struct {
+		field string
+		value float64
+	}{
+		{"paradise.policy.cpuRequestPercentile", p.CPURequestPercentile},
+		{"paradise.policy.cpuLimitPercentile", p.CPULimitPercentile},
+		{"paradise.policy.memRequestPercentile", p.MemRequestPercentile},
+		{"paradise.policy.memLimitPercentile", p.MemLimitPercentile},
+	}
+	for _, f := range percentiles {
+		if f.value < 0 || f.value > 100 {
+			add(f.field, "分位数需在 0-100 之间")
+		}
+	}
+	nonNegative := []struct {
+		field string
+		value float64
+	}{
+		{"paradise.policy.cpuHeadroom", p.CPUHeadroom},
+		{"paradise.policy.memHeadroom", p.MemHeadroom},
+		{"paradise.policy.lowCpu", float64(p.LowCPU)},
+		{"paradise.policy.highCpu", float64(p.HighCPU)},
+		{"paradise.policy.minCpuRequest", float64(p.MinCPURequest)},
+		{"paradise.policy.minCpuLimit", float64(p.MinCPULimit)},
+		{"paradise.policy.cpuRequestFactor", p.CPURequestFactor},
+		{"paradise.policy.cpuLimitFactor", p.CPULimitFactor},
+		{"paradise.policy.lowMem", float64(p.LowMem)},
+		{"paradise.policy.highMem", float64(p.HighMem)},
+		{"paradise.policy.minMemRequest", float64(p.MinMemRequest)},
+		{"paradise.policy.minMemLimit", float64(p.MinMemLimit)},
+		{"paradise.policy.memRequestFactor", p.MemRequestFactor},
+		{"paradise.policy.highMemRequestFactor", p.HighMemRequestFactor},
+		{"paradise.policy.memLimitFactor", p.MemLimitFactor},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			add(f.field, "不能为负数")
+		}
+	}
+}
+
+func validateRecommend(rc *RecommendConfig, add func(field, format string, args ...interface{})) {
+	known := make(map[string]bool)
+	for _, name := range BuiltinPolicies {
+		known[name] = true
+	}
+	for i, p := range rc.Policies {
+		field := fmt.Sprintf("recommend.policies[%d]", i)
+		if p.Name == "" {
+			add(field+".name", "策略名称不能为空")
+		} else if known[p.Name] {
+			add(field+".name", "策略名称 %q 重复或与内置策略重名", p.Name)
+		}
+		known[p.Name] = true
+		exprs := []struct {
+			field string
+			src   string
+		}{
+			{"cpuRequest", p.CPURequest},
+			{"cpuLimit", p.CPULimit},
+			{"memRequest", p.MemRequest},
+			{"memLimit", p.MemLimit},
+		}
+		for _, e := range exprs {
+			if e.src == "" {
+				add(field+"."+e.field, "表达式不能为空")
+			} else if _, err := expr.Compile(e.src, PolicyVariables); err != nil {
+				add(field+"."+e.field, "%v", err)
+			}
+		}
+	}
+	if rc.MinCPU < 0 {
+		add("recommend.minCpu", "不能为负数")
+	}
+	if rc.MinMemory < 0 {
+		add("recommend.minMemory", "不能为负数")
+	}
+	if rc.Policy != "" && !known[rc.Policy] {
+		add("recommend.policy", "未知策略 %q", rc.Policy)
+	}
+	for i, r := range rc.Rules {
+		field := fmt.Sprintf("recommend.rules[%d]", i)
+		if _, err := path.Match(r.Namespace, ""); err != nil {
+			add(field+".namespace", "通配符格式错误: %q", r.Namespace)
+		}
+		if _, err := path.Match(r.Workload, ""); err != nil {
+			add(field+".workload", "通配符格式错误: %q", r.Workload)
+		}
+		if r.Policy == "" {
+			add(field+".policy", "策略名称不能为空")
+		} else if !known[r.Policy] {
+			add(field+".policy", "未知策略 %q", r.Policy)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fields 返回校验错误中的字段路径，校验通过时返回 nil
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error 类型为 %T, want *ValidationError", err)
+	}
+	var result []string
+	for _, fe := range ve.Errors {
+		result = append(result, fe.Field)
+	}
+	return result
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{NameSpace: []string{"demo"}}
+	}
+	tests := []struct {
+		name     string
+		config   func(c *Config)
+		commands []string
+		want     []string
+	}{
+		{name: "最小配置", config: func(c *Config) {}},
+		{name: "缺少命名空间", config: func(c *Config) { c.NameSpace = nil }, want: []string{"namespace"}},
+		{name: "只有 namespaceSelector", config: func(c *Config) { c.NameSpace, c.NamespaceSelector = nil, "team=payments" }},
+		// 集群级子命令不读取 namespace
+		{name: "nodes 不需要命名空间", config: func(c *Config) { c.NameSpace = nil }, commands: []string{"nodes"}},
+		{name: "nodes 与其他子命令", config: func(c *Config) { c.NameSpace = nil }, commands: []string{"nodes", "cpu"}, want: []string{"namespace"}},
+		{name: "trend 缺少 Prometheus", config: func(c *Config) {}, commands: []string{"trend"}, want: []string{"prometheus"}},
+		{name: "resourceAdvisor 有 Prometheus", config: func(c *Config) { c.Prometheus = "http://prometheus:9090" }, commands: []string{"resourceAdvisor"}},
+		{name: "Prometheus 地址格式", config: func(c *Config) { c.Prometheus = "prometheus:9090" }, want: []string{"prometheus"}},
+		{name: "costEstimator 缺少价格", config: func(c *Config) {}, commands: []string{"costEstimator"}, want: []string{"cost.cpuPrice", "cost.totalCpu"}},
+		{
+			name:     "costEstimator prometheus 来源",
+			config:   func(c *Config) { c.Cost = Cost{CpuPrice: 4000, TotalCpu: 16, Source: CostSourcePrometheus} },
+			commands: []string{"costEstimator"},
+			want:     []string{"prometheus"},
+		},
+		{
+			name:     "paradise prometheus 来源",
+			config:   func(c *Config) { c.Paradise.Source = ParadiseSourcePrometheus },
+			commands: []string{"paradise", "simulate"},
+			want:     []string{"prometheus", "prometheus"},
+		},
+		// 未执行的子命令不检查必填字段，只检查已填写字段的格式
+		{name: "不检查其他子命令的必填字段", config: func(c *Config) { c.Cost.Model = "peak" }, commands: []string{"cpu"}, want: []string{"cost.model"}},
+		{
+			name: "一次返回全部问题",
+			config: func(c *Config) {
+				c.NameSpace = []string{"demo", " ", "[a"}
+				c.NamespaceSelector = "team in (a"
+				c.QPS = -1
+				c.Clusters = []Cluster{{Name: "a"}, {Name: "a"}, {}}
+				c.Sidecar.Patterns = []string{"("}
+				c.Simulate.NodeCPU = 4
+				c.Paradise.Policy.CPURequestPercentile = 101
+				c.Recommend.Policy = "missing"
+			},
+			commands: []string{"trend"},
+			want: []string{
+				"namespace[1]", "namespace[2]", "namespaceSelector", "qps", "clusters[1].name", "clusters[2].name",
+				"sidecar.patterns[0]", "simulate", "paradise.policy.cpuRequestPercentile", "recommend.policy", "prometheus",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.config(c)
+			if got := fields(t, Validate(c, tt.commands...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	if got := fields(t, Validate(nil)); !reflect.DeepEqual(got, []string{"(root)"}) {
+		t.Errorf("Validate(nil) fields = %q, want (root)", got)
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := Validate(&Config{}, "costEstimator")
+	want := "配置校验失败，共 3 个问题:\n" +
+		"  - namespace: 至少需要配置一个命名空间或 namespaceSelector\n" +
+		"  - cost.cpuPrice: costEstimator 需要配置大于 0 的机器价格\n" +
+		"  - cost.totalCpu: costEstimator 需要配置大于 0 的机器 CPU 核数"
+	if err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %q", err, want)
+	}
+}
@@ -1,24 +1,127 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix 环境变量前缀，字段路径转大写并以 _ 连接，如 cost.cpuPrice 对应 K8STOOLS_COST_CPUPRICE
+const EnvPrefix = "K8STOOLS"
+
+// LoadOptions 配置来源，优先级：命令行参数 > 环境变量 > 配置文件
+type LoadOptions struct {
+	Path     string         // 配置文件路径
+	Optional bool           // 为 true 时配置文件不存在不报错，只使用环境变量和命令行参数
+	Flags    *pflag.FlagSet // 与配置字段对应的参数（见 FlagName，如 --kubeconfig、--namespace-selector）会覆盖配置
+	Strict   bool           // 为 true 时配置文件中的未知字段作为 *ValidationError 返回，否则只输出警告
+}
+
+// ReadYaml 读取配置文件，文件不存在或解析失败时返回错误，不再返回 nil 配置
+// 环境变量同样生效，等价于 Load(LoadOptions{Path: path})
 func ReadYaml(path string) (c *Config, err error) {
-	// todo: 设置配置文件
-	viper.SetConfigFile(path)
-	// todo:  读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			fmt.Printf("未找到配置文件: %v", path)
+	return Load(LoadOptions{Path: path})
+}
+
+// Load 按 LoadOptions 合并配置文件、K8STOOLS_* 环境变量和命令行参数
+func Load(opts LoadOptions) (*Config, error) {
+	v := viper.New()
+
+	if opts.Path != "" {
+		if _, err := os.Stat(opts.Path); errors.Is(err, fs.ErrNotExist) {
+			if !opts.Optional {
+				return nil, fmt.Errorf("未找到配置文件: %v", opts.Path)
+			}
 		} else {
-			fmt.Printf("读取配置文件报错： %v", err)
+			v.SetConfigFile(opts.Path)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, fmt.Errorf("读取配置文件报错: %w", err)
+			}
+			unknown, err := UnknownKeys(opts.Path)
+			if err != nil {
+				return nil, err
+			}
+			if opts.Strict && len(unknown) > 0 {
+				return nil, &ValidationError{Errors: unknown}
+			}
+			for _, fe := range unknown {
+				fmt.Fprintf(os.Stderr, "⚠️ 配置文件 %s: %v\n", opts.Path, fe)
+			}
 		}
-		return nil, nil
 	}
-	if err := viper.Unmarshal(&c); err != nil {
+
+	// 逐个字段绑定环境变量和同名参数，未在配置文件中出现的字段也能被覆盖
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range Keys() {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("绑定环境变量 %s 失败: %w", key, err)
+		}
+		if opts.Flags == nil {
+			continue
+		}
+		if flag := opts.Flags.Lookup(FlagName(key)); flag != nil {
+			if err := v.BindPFlag(key, flag); err != nil {
+				return nil, fmt.Errorf("绑定参数 --%s 失败: %w", key, err)
+			}
+		}
+	}
+
+	var c Config
+	if err := v.Unmarshal(&c); err != nil {
 		return nil, fmt.Errorf("解析配置文件时出错: %w", err)
 	}
-	return c, nil
+	return &c, nil
+}
+
+// Keys 返回可通过环境变量覆盖的全部字段路径（json tag 以 . 连接）
+// clusters 等结构体列表无法用单个环境变量表达，只能在配置文件中填写
+func Keys() []string {
+	return keys(reflect.TypeOf(Config{}), "")
+}
+
+func keys(t reflect.Type, prefix string) []string {
+	var result []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		switch {
+		case f.Type.Kind() == reflect.Struct:
+			result = append(result, keys(f.Type, key+".")...)
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct:
+			continue
+		default:
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// EnvName 返回字段路径对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// FlagName 返回字段路径对应的命令行参数名，驼峰转为短横线，如 namespaceSelector 对应 --namespace-selector
+func FlagName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
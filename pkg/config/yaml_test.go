@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+prometheus: http://file:9090
+namespace: [file]
+cost:
+  cpuPrice: 1000
+  totalCpu: 8
+`)
+	tests := []struct {
+		name       string
+		env        map[string]string
+		flags      []string
+		prometheus string
+		namespace  []string
+		cpuPrice   int
+		kubeconfig string
+	}{
+		{name: "只有配置文件", prometheus: "http://file:9090", namespace: []string{"file"}, cpuPrice: 1000},
+		{
+			name:       "环境变量覆盖配置文件",
+			env:        map[string]string{"K8STOOLS_PROMETHEUS": "http://env:9090", "K8STOOLS_COST_CPUPRICE": "2000"},
+			prometheus: "http://env:9090", namespace: []string{"file"}, cpuPrice: 2000,
+		},
+		// 配置文件中没有的字段同样可以覆盖
+		{
+			name:       "环境变量补充配置文件中没有的字段",
+			env:        map[string]string{"K8STOOLS_KUBECONFIG": "/env/kubeconfig"},
+			prometheus: "http://file:9090", namespace: []string{"file"}, cpuPrice: 1000, kubeconfig: "/env/kubeconfig",
+		},
+		{
+			name:       "参数覆盖环境变量",
+			env:        map[string]string{"K8STOOLS_PROMETHEUS": "http://env:9090", "K8STOOLS_NAMESPACE": "env"},
+			flags:      []string{"--prometheus=http://flag:9090", "--namespace=a,b"},
+			prometheus: "http://flag:9090", namespace: []string{"a", "b"}, cpuPrice: 1000,
+		},
+		// 未显式传入的参数不覆盖，默认值也不生效
+		{
+			name:       "未传入的参数不覆盖",
+			env:        map[string]string{"K8STOOLS_PROMETHEUS": "http://env:9090"},
+			prometheus: "http://env:9090", namespace: []string{"file"}, cpuPrice: 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("prometheus", "http://default:9090", "")
+			flags.StringSlice("namespace", nil, "")
+			flags.String("kubeconfig", "", "")
+			if err := flags.Parse(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := Load(LoadOptions{Path: path, Flags: flags})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Prometheus != tt.prometheus || !reflect.DeepEqual(c.NameSpace, tt.namespace) ||
+				c.Cost.CpuPrice != tt.cpuPrice || c.KubeConfig != tt.kubeconfig {
+				t.Errorf("got prometheus=%s namespace=%v cpuPrice=%d kubeconfig=%q, want %s %v %d %q",
+					c.Prometheus, c.NameSpace, c.Cost.CpuPrice, c.KubeConfig,
+					tt.prometheus, tt.namespace, tt.cpuPrice, tt.kubeconfig)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := Load(LoadOptions{Path: path}); err == nil {
+		t.Error("配置文件不存在时应返回错误")
+	}
+
+	// Optional 时只使用环境变量
+	t.Setenv("K8STOOLS_NAMESPACE", "ci")
+	t.Setenv("K8STOOLS_PROMETHEUS", "http://ci:9090")
+	c, err := Load(LoadOptions{Path: path, Optional: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.NameSpace, []string{"ci"}) || c.Prometheus != "http://ci:9090" {
+		t.Errorf("got namespace=%v prometheus=%s, want [ci] http://ci:9090", c.NameSpace, c.Prometheus)
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		key, env, flag string
+	}{
+		{"kubeconfig", "K8STOOLS_KUBECONFIG", "kubeconfig"},
+		{"namespaceSelector", "K8STOOLS_NAMESPACESELECTOR", "namespace-selector"},
+		{"cost.cpuPrice", "K8STOOLS_COST_CPUPRICE", "cost.cpu-price"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.key); got != tt.env {
+			t.Errorf("EnvName(%s) = %s, want %s", tt.key, got, tt.env)
+		}
+		if got := FlagName(tt.key); got != tt.flag {
+			t.Errorf("FlagName(%s) = %s, want %s", tt.key, got, tt.flag)
+		}
+	}
+
+	// 结构体列表无法通过环境变量表达
+	keys := Keys()
+	for _, key := range []string{"namespace", "cost.cpuPrice", "paradise.policy.lowCpu"} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("Keys() 缺少 %s", key)
+		}
+	}
+	for _, key := range []string{"clusters", "recommend.policies", "recommend.rules"} {
+		if slices.Contains(keys, key) {
+			t.Errorf("Keys() 不应包含 %s", key)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package costEstimator 按容器 CPU、内存的 Requests 或实际使用量估算成本，并给出 Requests 超出使用量部分的闲置成本
+package costEstimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/sidecar"
+	"k8stools/pkg/workload"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mi  = 1024 * 1024
+	gib = 1024 * mi
+)
+
+// ContainerCost 单个容器的成本估算，CPU 单位为 m，内存单位为 Mi
+// CPUCost、MemCost 按配置的计费口径（Model）计算；IdleCost 为 Requests 超出使用量部分的成本，使用量高于 Requests 时记为 0
+type ContainerCost struct {
+	Cluster    string  `json:"cluster" header:"Cluster"`
+	Namespace  string  `json:"namespace" header:"Namespace"`
+	Kind       string  `json:"kind" header:"Kind"`
+	Workload   string  `json:"workload" header:"Workload"`
+	Pod        string  `json:"pod" header:"Pod"`
+	Container  string  `json:"container" header:"Container"`
+	CPURequest int64   `json:"cpuRequest" header:"CPU Request (m)"`
+	CPUUsage   int64   `json:"cpuUsage" header:"CPU Usage (m)"`
+	MemRequest int64   `json:"memRequest" header:"Mem Request (Mi)"`
+	MemUsage   int64   `json:"memUsage" header:"Mem Usage (Mi)"`
+	Model      string  `json:"model" header:"计费口径"`
+	CPUCost    float64 `json:"cpuCost" header:"CPU Cost ($)" fmt:"%.4f"`
+	MemCost    float64 `json:"memCost" header:"Mem Cost ($)" fmt:"%.4f"`
+	TotalCost  float64 `json:"totalCost" header:"Total Cost ($)" fmt:"%.4f"`
+	IdleCost   float64 `json:"idleCost" header:"Idle Cost ($)" fmt:"%.4f"`
+	Note       string  `json:"note" header:"说明"`
+}
+
+// GetCostEstimate 估算单个集群内配置的命名空间下每个容器的 CPU 与内存成本
+// CPU 单价为 cost.cpuPrice / cost.totalCpu（每核），内存单价为 cost.memPrice（每 GiB）；
+// 使用量来自 metrics-server（按 opts 采样取平均）或 Prometheus 窗口内的平均值（cost.source），与计费口径无关，
+// 计费口径只决定计费量，闲置成本始终按 Requests 与使用量计算。
+// 统计 Pod 的普通容器与原生 sidecar（restartPolicy 为 Always 的 init 容器），容器按 ownerReferences 归属到顶层工作负载，没有控制器的 Pod 记为 Kind=Pod
+// 个别命名空间失败时返回已估算的结果以及合并后的错误；使用量获取失败时按 Requests 计费，闲置成本记为 0
+func GetCostEstimate(ctx context.Context, c *config.Config, kc kube.Client, opts sampling.Options) ([]ContainerCost, error) {
+	cfg := *c
+	config.ApplyDefaults(&cfg)
+	cost := cfg.Cost
+
+	namespaces := c.NameSpace
+	clientset := kc.Kubernetes()
+
+	// 每个 CPU 核心的费用
+	cpuCostPerUnit := float64(cost.CpuPrice) / float64(cost.TotalCpu)
+
+	var rows []ContainerCost
+	var errs []error
+
+	var usages map[string]podUsage
+	var window time.Duration
+	if cost.Source == config.CostSourcePrometheus {
+		d, err := model.ParseDuration(cost.Window)
+		if err != nil {
+			return nil, fmt.Errorf("cost.window 格式错误: %w", err)
+		}
+		window = time.Duration(d)
+	} else {
+		var err error
+		usages, err = metricsUsage(ctx, kc.Metrics(), namespaces, opts)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, ns := range namespaces {
+		pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("无法获取命名空间 %s 的 Pods: %w", ns, err))
+			continue
+		}
+
+		// 归属解析失败时仍输出成本，工作负载记为 Pod 所属的直接控制器
+		owners, err := workload.LoadOwnerResolver(ctx, clientset, ns)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+			owners = workload.NewOwnerResolver(nil, nil)
+		}
+
+		nsUsage := usages[ns]
+		if cost.Source == config.CostSourcePrometheus {
+			nsUsage, err = prometheusUsage(ctx, c.Prometheus, ns, window)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+			}
+		}
+
+		for _, pod := range pods.Items {
+			owner, ok := owners.Resolve(&pod)
+			if !ok {
+				owner = workload.Owner{Kind: "Pod", Name: pod.Name}
+			}
+			for _, container := range containers(&pod) {
+				// 获取容器资源请求
+				cpuRequest := container.Resources.Requests[corev1.ResourceCPU]
+				memRequest := container.Resources.Requests[corev1.ResourceMemory]
+				cpuMilli := float64(cpuRequest.MilliValue()) // 毫核心
+				memBytes := float64(memRequest.Value())
+
+				u, hasUsage := nsUsage[pod.Name][container.Name]
+
+				// 按计费口径确定计费的 CPU、内存量
+				cpuBilled, memBilled := cpuMilli, memBytes
+				var note string
+				switch {
+				case !hasUsage && cost.Model == config.CostModelRequest:
+					note = "无使用量数据，闲置成本记为 0"
+				case !hasUsage:
+					note = "无使用量数据，按 Requests 计费，闲置成本记为 0"
+				case cost.Model == config.CostModelUsage:
+					cpuBilled, memBilled = u.CPU, u.Memory
+				case cost.Model == config.CostModelMax:
+					cpuBilled, memBilled = max(cpuMilli, u.CPU), max(memBytes, u.Memory)
+				}
+				cpuCost := cpuBilled * cpuCostPerUnit / 1000
+				memCost := memBilled / gib * cost.MemPrice
+
+				// 闲置成本：Requests 超出使用量的部分
+				var idleCost float64
+				if hasUsage {
+					idleCost = max(cpuMilli-u.CPU, 0)*cpuCostPerUnit/1000 + max(memBytes-u.Memory, 0)/gib*cost.MemPrice
+				}
+
+				rows = append(rows, ContainerCost{
+					Cluster:    kc.Name(),
+					Namespace:  ns,
+					Kind:       owner.Kind,
+					Workload:   owner.Name,
+					Pod:        pod.Name,
+					Container:  container.Name,
+					CPURequest: int64(cpuMilli),
+					CPUUsage:   int64(u.CPU + 0.5),
+					MemRequest: int64(memBytes / mi),
+					MemUsage:   int64(u.Memory/mi + 0.5),
+					Model:      cost.Model,
+					CPUCost:    cpuCost,
+					MemCost:    memCost,
+					TotalCost:  cpuCost + memCost,
+					IdleCost:   idleCost,
+					Note:       note,
+				})
+			}
+		}
+	}
+
+	return rows, errors.Join(errs...)
+}
+
+// containers 返回 Pod 运行期间占用资源的容器：原生 sidecar 与普通容器，其余 init 容器运行结束后不再占用资源
+func containers(pod *corev1.Pod) []corev1.Container {
+	var result []corev1.Container
+	for _, c := range pod.Spec.InitContainers {
+		if sidecar.IsNativeSidecar(c) {
+			result = append(result, c)
+		}
+	}
+	return append(result, pod.Spec.Containers...)
+}
@@ -0,0 +1,183 @@
+package costEstimator
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sampling"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+const ns = "demo"
+
+func list(cpu, mem string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)}
+}
+
+func container(name, cpu, mem string) corev1.Container {
+	return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: list(cpu, mem)}}
+}
+
+// newClient web 的 Pod 含主容器 app、原生 sidecar mesh 与普通 init 容器 migrate；
+// solo 的使用量高于 Requests；idle 没有使用量数据
+func newClient(t *testing.T) kube.Client {
+	t.Helper()
+	isController := true
+	always := corev1.ContainerRestartPolicyAlways
+	mesh := container("mesh", "100m", "128Mi")
+	mesh.RestartPolicy = &always
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web", UID: "d-web"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns, Name: "web-7d9f", UID: "rs-web",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "d-web", Controller: &isController}},
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns, Name: "web-7d9f-a",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f", UID: "rs-web", Controller: &isController}},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("migrate", "1", "1Gi"), mesh},
+				Containers:     []corev1.Container{container("app", "500m", "1Gi")},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "solo"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container("app", "100m", "64Mi")}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "idle"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container("app", "200m", "256Mi")}},
+		},
+	)
+
+	metricsClient := metricsfake.NewSimpleClientset()
+	gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	for _, m := range []*metricsv1beta1.PodMetrics{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web-7d9f-a"},
+			Containers: []metricsv1beta1.ContainerMetrics{
+				{Name: "app", Usage: list("200m", "512Mi")},
+				{Name: "mesh", Usage: list("50m", "64Mi")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "solo"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: list("300m", "128Mi")}},
+		},
+	} {
+		if err := metricsClient.Tracker().Create(gvr, m, ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return kube.NewForClients("test", clientset, metricsClient, nil)
+}
+
+// expected 单个容器的成本，CPU 每核 250 元，内存每 GiB 20 元
+type expected struct {
+	total, idle float64
+	note        string
+}
+
+func TestGetCostEstimate(t *testing.T) {
+	const noUsage = "无使用量数据，按 Requests 计费，闲置成本记为 0"
+	// 闲置成本与计费口径无关：web/app (0.3 核, 0.5GiB)、web/mesh (0.05 核, 64Mi)，solo 使用量高于 Requests 记为 0
+	tests := []struct {
+		model string
+		want  map[string]expected
+	}{
+		{
+			model: config.CostModelRequest,
+			want: map[string]expected{
+				"web-7d9f-a/app":  {total: 145, idle: 85},
+				"web-7d9f-a/mesh": {total: 27.5, idle: 13.75},
+				"solo/app":        {total: 26.25},
+				"idle/app":        {total: 55, note: "无使用量数据，闲置成本记为 0"},
+			},
+		},
+		{
+			model: config.CostModelUsage,
+			want: map[string]expected{
+				"web-7d9f-a/app":  {total: 60, idle: 85},
+				"web-7d9f-a/mesh": {total: 13.75, idle: 13.75},
+				"solo/app":        {total: 77.5},
+				"idle/app":        {total: 55, note: noUsage},
+			},
+		},
+		{
+			model: config.CostModelMax,
+			want: map[string]expected{
+				"web-7d9f-a/app":  {total: 145, idle: 85},
+				"web-7d9f-a/mesh": {total: 27.5, idle: 13.75},
+				"solo/app":        {total: 77.5},
+				"idle/app":        {total: 55, note: noUsage},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			c := &config.Config{
+				NameSpace: []string{ns},
+				Cost:      config.Cost{CpuPrice: 4000, TotalCpu: 16, MemPrice: 20, Model: tt.model},
+			}
+			rows, err := GetCostEstimate(context.Background(), c, newClient(t), sampling.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 普通 init 容器 migrate 运行结束后不占用资源，不计入
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.want))
+			}
+			for _, r := range rows {
+				key := r.Pod + "/" + r.Container
+				want, ok := tt.want[key]
+				if !ok {
+					t.Errorf("unexpected row %s", key)
+					continue
+				}
+				if math.Abs(r.TotalCost-want.total) > 1e-9 || math.Abs(r.IdleCost-want.idle) > 1e-9 || r.Note != want.note {
+					t.Errorf("%s: total=%g idle=%g note=%q, want total=%g idle=%g note=%q",
+						key, r.TotalCost, r.IdleCost, r.Note, want.total, want.idle, want.note)
+				}
+				if r.Model != tt.model {
+					t.Errorf("%s: model = %s, want %s", key, r.Model, tt.model)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCostEstimateUsageColumns(t *testing.T) {
+	// 默认的 request 口径同样输出使用量
+	c := &config.Config{NameSpace: []string{ns}, Cost: config.Cost{CpuPrice: 4000, TotalCpu: 16}}
+	rows, err := GetCostEstimate(context.Background(), c, newClient(t), sampling.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if r.Pod != "web-7d9f-a" || r.Container != "app" {
+			continue
+		}
+		got := [4]int64{r.CPURequest, r.CPUUsage, r.MemRequest, r.MemUsage}
+		if want := [4]int64{500, 200, 1024, 512}; got != want {
+			t.Errorf("web/app = %v, want %v", got, want)
+		}
+		if r.Kind != "Deployment" || r.Workload != "web" || r.Model != config.CostModelRequest {
+			t.Errorf("web/app = %s/%s model %s, want Deployment/web model request", r.Kind, r.Workload, r.Model)
+		}
+		return
+	}
+	t.Fatal("没有 web-7d9f-a/app 的结果")
+}
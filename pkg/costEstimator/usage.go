@@ -0,0 +1,126 @@
+package costEstimator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8stools/pkg/sampling"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// usage 单个容器的平均使用量，CPU 单位为 m，Memory 单位为字节
+type usage struct {
+	CPU    float64
+	Memory float64
+}
+
+// podUsage Pod 名 -> 容器名 -> 平均使用量
+type podUsage map[string]map[string]usage
+
+func (p podUsage) add(pod, container string, fn func(u *usage)) {
+	if p[pod] == nil {
+		p[pod] = make(map[string]usage)
+	}
+	u := p[pod][container]
+	fn(&u)
+	p[pod][container] = u
+}
+
+// metricsUsage 按 opts 采样 metrics-server，返回命名空间 -> 各容器在全部采样中的平均值
+// 只读一次瞬时值时即为当前使用量
+func metricsUsage(ctx context.Context, metricsClient metrics.Interface, namespaces []string, opts sampling.Options) (map[string]podUsage, error) {
+	samples, err := sampling.Collect(ctx, metricsClient, namespaces, opts)
+	result := make(map[string]podUsage, len(samples))
+	for ns, rounds := range samples {
+		type sum struct {
+			cpu, mem float64
+			n        int
+		}
+		sums := make(map[string]map[string]*sum)
+		for _, sample := range rounds {
+			for pod, containers := range sample {
+				if sums[pod] == nil {
+					sums[pod] = make(map[string]*sum)
+				}
+				for name, u := range containers {
+					s := sums[pod][name]
+					if s == nil {
+						s = &sum{}
+						sums[pod][name] = s
+					}
+					s.cpu += float64(u.CPU)
+					s.mem += float64(u.Memory)
+					s.n++
+				}
+			}
+		}
+		pu := make(podUsage, len(sums))
+		for pod, containers := range sums {
+			for name, s := range containers {
+				pu.add(pod, name, func(u *usage) {
+					u.CPU = s.cpu / float64(s.n)
+					u.Memory = s.mem / float64(s.n)
+				})
+			}
+		}
+		result[ns] = pu
+	}
+	return result, err
+}
+
+// prometheusUsage 查询命名空间 ns 下各容器在 window 内的平均使用量：
+// CPU 为整个窗口的 rate，内存为 working set 的 avg_over_time
+func prometheusUsage(ctx context.Context, address, ns string, window time.Duration) (podUsage, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("创建 Prometheus 客户端失败: %w", err)
+	}
+	promAPI := v1.NewAPI(client)
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	filter := fmt.Sprintf(`namespace=%q,image!="",container!="",container!="POD"`, ns)
+	rng := model.Duration(window).String()
+	queries := []struct {
+		name  string
+		query string
+		set   func(u *usage, v float64)
+	}{
+		{
+			name:  "CPU",
+			query: fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{%s}[%s])) by (pod, container)`, filter, rng),
+			set:   func(u *usage, v float64) { u.CPU = v * 1000 },
+		},
+		{
+			name:  "内存",
+			query: fmt.Sprintf(`max(avg_over_time(container_memory_working_set_bytes{%s}[%s])) by (pod, container)`, filter, rng),
+			set:   func(u *usage, v float64) { u.Memory = v },
+		},
+	}
+
+	pu := make(podUsage)
+	for _, q := range queries {
+		result, warnings, err := promAPI.Query(ctx, q.query, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("查询 %s 失败: %w", q.name, err)
+		}
+		if len(warnings) > 0 {
+			fmt.Fprintf(os.Stderr, "%s 查询警告: %v\n", q.name, warnings)
+		}
+		vector, ok := result.(model.Vector)
+		if !ok {
+			continue
+		}
+		for _, s := range vector {
+			v := float64(s.Value)
+			pu.add(string(s.Metric["pod"]), string(s.Metric["container"]), func(u *usage) { q.set(u, v) })
+		}
+	}
+	return pu, nil
+}
@@ -0,0 +1,208 @@
+// Package cpu 统计工作负载维度的 CPU/内存使用量、Requests/Limits、Requests 利用率与 HPA 状态
+package cpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8stools/pkg/config"
+	"k8stools/pkg/hpa"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/sidecar"
+	"k8stools/pkg/workload"
+)
+
+// DeploymentCPU 工作负载维度的 CPU 与内存统计，主容器与 sidecar 分开计算（sidecar 识别规则见 pkg/sidecar）
+// 内存单位为 MiB，利用率为全部容器使用量占 Requests 的百分比，Requests 为 0 时记为 0
+// 使用量为采样窗口内的平均值，Min/P95/Max 为每轮采样中工作负载全部容器合计值的统计，只采样一次时与瞬时值相同
+// 没有 HPA 的工作负载 Min/Max Replicas 均为期望副本数，HPA 相关列为空
+type DeploymentCPU struct {
+	Cluster            string  `json:"cluster" header:"Cluster"`
+	Namespace          string  `json:"namespace" header:"Namespace"`
+	Kind               string  `json:"kind" header:"Kind"`
+	Workload           string  `json:"workload" header:"Workload"`
+	MainCPUUsage       int64   `json:"mainCpuUsage" header:"Main CPU Usage (m)"`
+	SidecarCPUUsage    int64   `json:"sidecarCpuUsage" header:"Sidecar CPU Usage (m)"`
+	MainCPURequests    int64   `json:"mainCpuRequests" header:"Main CPU Requests (m)"`
+	SidecarCPURequests int64   `json:"sidecarCpuRequests" header:"Sidecar CPU Requests (m)"`
+	MainCPULimits      int64   `json:"mainCpuLimits" header:"Main CPU Limits (m)"`
+	SidecarCPULimits   int64   `json:"sidecarCpuLimits" header:"Sidecar CPU Limits (m)"`
+	MainMemUsage       int64   `json:"mainMemUsage" header:"Main Mem Usage (Mi)"`
+	SidecarMemUsage    int64   `json:"sidecarMemUsage" header:"Sidecar Mem Usage (Mi)"`
+	MainMemRequests    int64   `json:"mainMemRequests" header:"Main Mem Requests (Mi)"`
+	SidecarMemRequests int64   `json:"sidecarMemRequests" header:"Sidecar Mem Requests (Mi)"`
+	MainMemLimits      int64   `json:"mainMemLimits" header:"Main Mem Limits (Mi)"`
+	SidecarMemLimits   int64   `json:"sidecarMemLimits" header:"Sidecar Mem Limits (Mi)"`
+	CPURequestUtil     float64 `json:"cpuRequestUtil" header:"CPU Req Util (%)" fmt:"%.1f"`
+	MemRequestUtil     float64 `json:"memRequestUtil" header:"Mem Req Util (%)" fmt:"%.1f"`
+	Samples            int     `json:"samples" header:"Samples"`
+	CPUUsageMin        int64   `json:"cpuUsageMin" header:"CPU Usage Min (m)"`
+	CPUUsageP95        int64   `json:"cpuUsageP95" header:"CPU Usage P95 (m)"`
+	CPUUsageMax        int64   `json:"cpuUsageMax" header:"CPU Usage Max (m)"`
+	MemUsageMin        int64   `json:"memUsageMin" header:"Mem Usage Min (Mi)"`
+	MemUsageP95        int64   `json:"memUsageP95" header:"Mem Usage P95 (Mi)"`
+	MemUsageMax        int64   `json:"memUsageMax" header:"Mem Usage Max (Mi)"`
+	MinReplicas        int32   `json:"minReplicas" header:"Pod Min Replicas"`
+	MaxReplicas        int32   `json:"maxReplicas" header:"Pod Max Replicas"`
+	HPA                string  `json:"hpa,omitempty" header:"HPA"`
+	HPATargets         string  `json:"hpaTargets,omitempty" header:"HPA Targets"`
+	CurrentReplicas    int32   `json:"currentReplicas,omitempty" header:"HPA Current"`
+	DesiredReplicas    int32   `json:"desiredReplicas,omitempty" header:"HPA Desired"`
+	AbleToScale        string  `json:"ableToScale,omitempty" header:"AbleToScale"`
+	ScalingLimited     string  `json:"scalingLimited,omitempty" header:"ScalingLimited"`
+	AtMaxReplicas      bool    `json:"atMaxReplicas" header:"At Max"`
+}
+
+// GetDeploymentCpu 统计单个集群内配置的命名空间下所有工作负载（见 workload.Kinds）的 CPU 与内存情况
+// 使用量按 opts 采样，所有命名空间在同一窗口内采集；metrics-server 不可用时仍输出 Requests/Limits，使用量记为 0
+// 个别命名空间失败时返回已统计的结果以及合并后的错误
+func GetDeploymentCpu(ctx context.Context, c *config.Config, kc kube.Client, opts sampling.Options) ([]DeploymentCPU, error) {
+	classifier, err := sidecar.New(c.Sidecar)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	samples, err := sampling.Collect(ctx, kc.Metrics(), c.NameSpace, opts)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var rows []DeploymentCPU
+	for _, ns := range c.NameSpace {
+		nsRows, err := collectDeploymentStats(ctx, kc.Name(), ns, kc.Kubernetes(), samples[ns], classifier)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+		}
+		rows = append(rows, nsRows...)
+	}
+	return rows, errors.Join(errs...)
+}
+
+func collectDeploymentStats(ctx context.Context, cluster, ns string, clientset kubernetes.Interface, samples []sampling.Sample, classifier *sidecar.Classifier) ([]DeploymentCPU, error) {
+	// 个别类型的工作负载获取失败时仍统计其余类型
+	var errs []error
+	workloads, owners, err := workload.List(ctx, clientset, ns)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Pod 失败: %w", err)
+	}
+
+	// HPA 按 Kind/Name 匹配扩缩容目标，获取失败时按未配置 HPA 输出
+	hpas, err := hpa.List(ctx, clientset, ns)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// 按 ownerReferences 归属 Pod，不依赖标签选择器
+	podsByOwner := owners.Group(pods.Items)
+
+	var rows []DeploymentCPU
+	for _, w := range workloads {
+		var mainRequest, sidecarRequest, mainLimit, sidecarLimit int64
+		var mainMemRequest, sidecarMemRequest, mainMemLimit, sidecarMemLimit int64
+		// 每轮采样的合计使用量
+		mainUsage := make([]int64, len(samples))
+		sidecarUsage := make([]int64, len(samples))
+		mainMemUsage := make([]int64, len(samples))
+		sidecarMemUsage := make([]int64, len(samples))
+		for _, pod := range podsByOwner[w.Key()] {
+			mainContainers, sidecars := classifier.Split(&pod)
+			for _, c := range mainContainers {
+				mainRequest += c.Resources.Requests.Cpu().MilliValue()
+				mainLimit += c.Resources.Limits.Cpu().MilliValue()
+				mainMemRequest += c.Resources.Requests.Memory().Value()
+				mainMemLimit += c.Resources.Limits.Memory().Value()
+			}
+			for _, c := range sidecars {
+				sidecarRequest += c.Resources.Requests.Cpu().MilliValue()
+				sidecarLimit += c.Resources.Limits.Cpu().MilliValue()
+				sidecarMemRequest += c.Resources.Requests.Memory().Value()
+				sidecarMemLimit += c.Resources.Limits.Memory().Value()
+			}
+			for i, sample := range samples {
+				metrics := sample[pod.Name]
+				for _, c := range mainContainers {
+					mainUsage[i] += metrics[c.Name].CPU
+					mainMemUsage[i] += metrics[c.Name].Memory
+				}
+				for _, c := range sidecars {
+					sidecarUsage[i] += metrics[c.Name].CPU
+					sidecarMemUsage[i] += metrics[c.Name].Memory
+				}
+			}
+		}
+
+		totalUsage := make([]int64, len(samples))
+		totalMemUsage := make([]int64, len(samples))
+		for i := range samples {
+			totalUsage[i] = mainUsage[i] + sidecarUsage[i]
+			totalMemUsage[i] = mainMemUsage[i] + sidecarMemUsage[i]
+		}
+		cpuStats := sampling.Summarize(totalUsage)
+		memStats := sampling.Summarize(totalMemUsage)
+
+		row := DeploymentCPU{
+			Cluster:            cluster,
+			Namespace:          ns,
+			Kind:               w.Kind,
+			Workload:           w.Name,
+			MainCPUUsage:       sampling.Summarize(mainUsage).Avg,
+			SidecarCPUUsage:    sampling.Summarize(sidecarUsage).Avg,
+			MainCPURequests:    mainRequest,
+			SidecarCPURequests: sidecarRequest,
+			MainCPULimits:      mainLimit,
+			SidecarCPULimits:   sidecarLimit,
+			MainMemUsage:       mebibytes(sampling.Summarize(mainMemUsage).Avg),
+			SidecarMemUsage:    mebibytes(sampling.Summarize(sidecarMemUsage).Avg),
+			MainMemRequests:    mebibytes(mainMemRequest),
+			SidecarMemRequests: mebibytes(sidecarMemRequest),
+			MainMemLimits:      mebibytes(mainMemLimit),
+			SidecarMemLimits:   mebibytes(sidecarMemLimit),
+			CPURequestUtil:     utilization(cpuStats.Avg, mainRequest+sidecarRequest),
+			MemRequestUtil:     utilization(memStats.Avg, mainMemRequest+sidecarMemRequest),
+			Samples:            len(samples),
+			CPUUsageMin:        cpuStats.Min,
+			CPUUsageP95:        cpuStats.P95,
+			CPUUsageMax:        cpuStats.Max,
+			MemUsageMin:        mebibytes(memStats.Min),
+			MemUsageP95:        mebibytes(memStats.P95),
+			MemUsageMax:        mebibytes(memStats.Max),
+			MinReplicas:        w.Replicas,
+			MaxReplicas:        w.Replicas,
+		}
+		if status, ok := hpas[w.Key()]; ok {
+			row.MinReplicas = status.MinReplicas
+			row.MaxReplicas = status.MaxReplicas
+			row.HPA = status.Name
+			row.HPATargets = status.Targets
+			row.CurrentReplicas = status.CurrentReplicas
+			row.DesiredReplicas = status.DesiredReplicas
+			row.AbleToScale = status.AbleToScale
+			row.ScalingLimited = status.ScalingLimited
+			row.AtMaxReplicas = status.AtMax
+		}
+		rows = append(rows, row)
+	}
+	return rows, errors.Join(errs...)
+}
+
+func mebibytes(bytes int64) int64 {
+	return bytes / (1024 * 1024)
+}
+
+// utilization 使用量占 Requests 的百分比，未设置 Requests 时返回 0
+func utilization(used, requested int64) float64 {
+	if requested == 0 {
+		return 0
+	}
+	return float64(used) / float64(requested) * 100
+}
@@ -0,0 +1,169 @@
+package cpu
+
+import (
+	"context"
+	"testing"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sampling"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+const ns = "demo"
+
+func resources(cpu, mem string) corev1.ResourceRequirements {
+	list := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(mem),
+	}
+	return corev1.ResourceRequirements{Requests: list, Limits: list}
+}
+
+func pod(name, rsName string, rsUID types.UID) *corev1.Pod {
+	isController := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: rsName, UID: rsUID, Controller: &isController},
+			},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Resources: resources("200m", "256Mi")},
+			{Name: "istio-proxy", Resources: resources("100m", "128Mi")},
+		}},
+	}
+}
+
+func podMetrics(name string, appCPU, sidecarCPU string) *metricsv1beta1.PodMetrics {
+	usage := func(cpu, mem string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)}
+	}
+	return &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: usage(appCPU, "128Mi")},
+			{Name: "istio-proxy", Usage: usage(sidecarCPU, "64Mi")},
+		},
+	}
+}
+
+// newClient 构建包含一个带 HPA 的 Deployment（2 个 Pod）和一个没有 Pod 的 StatefulSet 的 fake 集群
+func newClient(t *testing.T, withMetrics bool) kube.Client {
+	t.Helper()
+	isController := true
+	replicas := int32(2)
+	minReplicas := int32(2)
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web", UID: "d-web"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns, Name: "web-7d9f", UID: "rs-web",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "d-web", Controller: &isController}},
+		}},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "db", UID: "s-db"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		},
+		pod("web-7d9f-a", "web-7d9f", "rs-web"),
+		pod("web-7d9f-b", "web-7d9f", "rs-web"),
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    4,
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 4, DesiredReplicas: 4},
+		},
+	}
+
+	metricsClient := metricsfake.NewSimpleClientset()
+	if withMetrics {
+		// metrics 的 fake clientset 按资源名 "pods" 查询 PodMetrics，直接写入 tracker
+		gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+		for _, m := range []*metricsv1beta1.PodMetrics{podMetrics("web-7d9f-a", "100m", "20m"), podMetrics("web-7d9f-b", "300m", "40m")} {
+			if err := metricsClient.Tracker().Create(gvr, m, ns); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return kube.NewForClients("test", fake.NewSimpleClientset(objects...), metricsClient, nil)
+}
+
+func TestGetDeploymentCpu(t *testing.T) {
+	tests := []struct {
+		name        string
+		withMetrics bool
+		want        map[string]DeploymentCPU
+	}{
+		{
+			name:        "有使用量",
+			withMetrics: true,
+			want: map[string]DeploymentCPU{
+				"Deployment/web": {
+					MainCPUUsage: 400, SidecarCPUUsage: 60,
+					MainCPURequests: 400, SidecarCPURequests: 200, MainCPULimits: 400, SidecarCPULimits: 200,
+					MainMemUsage: 256, SidecarMemUsage: 128, MainMemRequests: 512, SidecarMemRequests: 256,
+					MainMemLimits: 512, SidecarMemLimits: 256,
+					CPURequestUtil: 460.0 / 600 * 100, MemRequestUtil: 50,
+					Samples: 1, CPUUsageMin: 460, CPUUsageP95: 460, CPUUsageMax: 460,
+					MemUsageMin: 384, MemUsageP95: 384, MemUsageMax: 384,
+					MinReplicas: 2, MaxReplicas: 4, HPA: "web", CurrentReplicas: 4, DesiredReplicas: 4, AtMaxReplicas: true,
+				},
+				"StatefulSet/db": {Samples: 1, MinReplicas: 2, MaxReplicas: 2},
+			},
+		},
+		{
+			name: "metrics-server 无数据时使用量为 0",
+			want: map[string]DeploymentCPU{
+				"Deployment/web": {
+					MainCPURequests: 400, SidecarCPURequests: 200, MainCPULimits: 400, SidecarCPULimits: 200,
+					MainMemRequests: 512, SidecarMemRequests: 256, MainMemLimits: 512, SidecarMemLimits: 256,
+					Samples: 1, MinReplicas: 2, MaxReplicas: 4, HPA: "web", CurrentReplicas: 4, DesiredReplicas: 4, AtMaxReplicas: true,
+				},
+				"StatefulSet/db": {Samples: 1, MinReplicas: 2, MaxReplicas: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{NameSpace: []string{ns}}
+			rows, err := GetDeploymentCpu(context.Background(), c, newClient(t, tt.withMetrics), sampling.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.want))
+			}
+			for _, row := range rows {
+				key := row.Kind + "/" + row.Workload
+				want, ok := tt.want[key]
+				if !ok {
+					t.Errorf("unexpected row %s", key)
+					continue
+				}
+				// 只比较数值列，HPA Targets 等展示列由 pkg/hpa 负责
+				row.Cluster, row.Namespace, row.Kind, row.Workload = "", "", "", ""
+				row.HPATargets, row.AbleToScale, row.ScalingLimited = "", "", ""
+				if row != want {
+					t.Errorf("%s =\n%+v\nwant\n%+v", key, row, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package expr 解析并计算建议策略中的算术表达式，如 "max(p95 * 1.2, avg + 100)"
+// 支持数字、变量、+ - * /、括号以及 max()、min()、ceil() 函数
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+)
+
+// Expr 已解析的表达式
+type Expr struct {
+	src  string
+	node ast.Expr
+}
+
+// Compile 解析表达式，vars 为允许使用的变量名
+func Compile(src string, vars []string) (*Expr, error) {
+	node, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("表达式 %q 语法错误: %w", src, err)
+	}
+	allowed := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		allowed[v] = true
+	}
+	if err := check(node, allowed); err != nil {
+		return nil, fmt.Errorf("表达式 %q: %w", src, err)
+	}
+	return &Expr{src: src, node: node}, nil
+}
+
+// String 返回原始表达式
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval 使用 vars 中的变量值计算表达式，除数为 0 时结果为 0
+func (e *Expr) Eval(vars map[string]float64) float64 {
+	return eval(e.node, vars)
+}
+
+var functions = map[string]int{"max": 2, "min": 2, "ceil": 1}
+
+func check(node ast.Expr, vars map[string]bool) error {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return fmt.Errorf("不支持的字面量 %s", n.Value)
+		}
+	case *ast.Ident:
+		if !vars[n.Name] {
+			return fmt.Errorf("未知变量 %s", n.Name)
+		}
+	case *ast.ParenExpr:
+		return check(n.X, vars)
+	case *ast.UnaryExpr:
+		if n.Op != token.SUB && n.Op != token.ADD {
+			return fmt.Errorf("不支持的运算符 %s", n.Op)
+		}
+		return check(n.X, vars)
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO:
+		default:
+			return fmt.Errorf("不支持的运算符 %s", n.Op)
+		}
+		if err := check(n.X, vars); err != nil {
+			return err
+		}
+		return check(n.Y, vars)
+	case *ast.CallExpr:
+		fn, ok := n.Fun.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("不支持的函数调用")
+		}
+		argc, ok := functions[fn.Name]
+		if !ok {
+			return fmt.Errorf("未知函数 %s", fn.Name)
+		}
+		if len(n.Args) != argc {
+			return fmt.Errorf("函数 %s 需要 %d 个参数", fn.Name, argc)
+		}
+		for _, arg := range n.Args {
+			if err := check(arg, vars); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的表达式")
+	}
+	return nil
+}
+
+func eval(node ast.Expr, vars map[string]float64) float64 {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		v, _ := strconv.ParseFloat(n.Value, 64)
+		return v
+	case *ast.Ident:
+		return vars[n.Name]
+	case *ast.ParenExpr:
+		return eval(n.X, vars)
+	case *ast.UnaryExpr:
+		if n.Op == token.SUB {
+			return -eval(n.X, vars)
+		}
+		return eval(n.X, vars)
+	case *ast.BinaryExpr:
+		x, y := eval(n.X, vars), eval(n.Y, vars)
+		switch n.Op {
+		case token.ADD:
+			return x + y
+		case token.SUB:
+			return x - y
+		case token.MUL:
+			return x * y
+		case token.QUO:
+			if y == 0 {
+				return 0
+			}
+			return x / y
+		}
+	case *ast.CallExpr:
+		fn := n.Fun.(*ast.Ident).Name
+		switch fn {
+		case "max":
+			return math.Max(eval(n.Args[0], vars), eval(n.Args[1], vars))
+		case "min":
+			return math.Min(eval(n.Args[0], vars), eval(n.Args[1], vars))
+		case "ceil":
+			return math.Ceil(eval(n.Args[0], vars))
+		}
+	}
+	return 0
+}
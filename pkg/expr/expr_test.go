@@ -0,0 +1,83 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var testVars = []string{"min", "avg", "p95", "max"}
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"min": 10, "avg": 40, "p95": 90, "max": 120}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"p95 * 1.2", 108},
+		// 乘除优先于加减，括号改变优先级
+		{"avg + p95 * 2", 220},
+		{"(avg + p95) * 2", 260},
+		{"max - avg / 4 * 2", 100},
+		{"max - avg - min", 70},
+		{"-min + 5", -5},
+		{"+min", 10},
+		// 除数为 0 时结果为 0
+		{"avg / 0", 0},
+		{"avg / (max - max)", 0},
+		{"p95 + avg / 0", 90},
+		// max、min 既是变量也是函数
+		{"max(p95 * 1.2, max)", 120},
+		{"min(avg, 100)", 40},
+		{"max(min, 50)", 50},
+		{"min(max(avg, 20), 30)", 30},
+		{"ceil(avg / 3)", 14},
+		{"max(ceil(min * 1.05), 11.5)", 11.5},
+		{"1e2 + 0.5", 100.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Compile(tt.src, testVars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Eval(vars); got != tt.want {
+				t.Errorf("Eval(%q) = %g, want %g", tt.src, got, tt.want)
+			}
+			if e.String() != tt.src {
+				t.Errorf("String() = %q, want %q", e.String(), tt.src)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"p99 * 1.2", "未知变量 p99"},
+		{"p95 * factor", "未知变量 factor"},
+		{"avg(p95, max)", "未知函数 avg"},
+		{"floor(p95)", "未知函数 floor"},
+		{"max(p95)", "函数 max 需要 2 个参数"},
+		{"ceil(p95, 2)", "函数 ceil 需要 1 个参数"},
+		{"math.Max(p95, max)", "不支持的函数调用"},
+		{"p95 % 2", "不支持的运算符 %"},
+		{"!p95", "不支持的运算符 !"},
+		{`"100m"`, "不支持的字面量"},
+		{"p95[0]", "不支持的表达式"},
+		{"p95 *", "语法错误"},
+		{"", "语法错误"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, testVars)
+			if err == nil {
+				t.Fatalf("Compile(%q) 应返回错误", tt.src)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile(%q) error = %q, want containing %q", tt.src, err, tt.want)
+			}
+		})
+	}
+}
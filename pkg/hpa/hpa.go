@@ -0,0 +1,183 @@
+// Package hpa 通过 autoscaling/v2 读取 HPA 的副本范围、目标指标、当前状态与扩缩容条件
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Status 单个 HPA 的配置与当前状态
+type Status struct {
+	Name            string
+	MinReplicas     int32
+	MaxReplicas     int32
+	CurrentReplicas int32
+	DesiredReplicas int32
+	// Targets 目标指标，格式与 kubectl get hpa 的 TARGETS 列一致，如 "cpu: 45%/80%"
+	Targets string
+	// AbleToScale、ScalingLimited 为对应 Condition 的状态（True/False/Unknown），未上报时为空
+	AbleToScale    string
+	ScalingLimited string
+	// AtMax 当前副本数已达到 maxReplicas，扩容被上限卡住
+	AtMax bool
+}
+
+// List 获取命名空间下的 HPA，键为扩缩容目标的 "Kind/Name"，与 workload.Workload.Key 对应
+func List(ctx context.Context, clientset kubernetes.Interface, ns string) (map[string]Status, error) {
+	list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 HPA 失败: %w", err)
+	}
+	res := make(map[string]Status, len(list.Items))
+	for i := range list.Items {
+		h := &list.Items[i]
+		res[h.Spec.ScaleTargetRef.Kind+"/"+h.Spec.ScaleTargetRef.Name] = FromHPA(h)
+	}
+	return res, nil
+}
+
+// FromHPA 提取 HPA 的配置与状态，minReplicas 未设置时按 API Server 默认值 1 处理
+func FromHPA(h *autoscalingv2.HorizontalPodAutoscaler) Status {
+	s := Status{
+		Name:            h.Name,
+		MinReplicas:     1,
+		MaxReplicas:     h.Spec.MaxReplicas,
+		CurrentReplicas: h.Status.CurrentReplicas,
+		DesiredReplicas: h.Status.DesiredReplicas,
+		Targets:         FormatTargets(h),
+		AbleToScale:     conditionStatus(h, autoscalingv2.AbleToScale),
+		ScalingLimited:  conditionStatus(h, autoscalingv2.ScalingLimited),
+	}
+	if h.Spec.MinReplicas != nil {
+		s.MinReplicas = *h.Spec.MinReplicas
+	}
+	s.AtMax = s.MaxReplicas > 0 && s.CurrentReplicas >= s.MaxReplicas
+	return s
+}
+
+func conditionStatus(h *autoscalingv2.HorizontalPodAutoscaler, t autoscalingv2.HorizontalPodAutoscalerConditionType) string {
+	for _, c := range h.Status.Conditions {
+		if c.Type == t {
+			return string(c.Status)
+		}
+	}
+	return ""
+}
+
+// FormatTargets 按 "指标: 当前值/目标值" 格式化全部目标指标，以逗号分隔，尚无当前值时记为 <unknown>
+func FormatTargets(h *autoscalingv2.HorizontalPodAutoscaler) string {
+	var parts []string
+	for _, m := range h.Spec.Metrics {
+		name, target := describeSpec(m)
+		current := "<unknown>"
+		for _, cm := range h.Status.CurrentMetrics {
+			if v, ok := describeStatus(m, cm); ok {
+				current = v
+				break
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s/%s", name, current, target))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeSpec 返回指标名称与目标值
+func describeSpec(m autoscalingv2.MetricSpec) (string, string) {
+	switch m.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if m.Resource != nil {
+			return string(m.Resource.Name), formatTarget(m.Resource.Target)
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if m.ContainerResource != nil {
+			return fmt.Sprintf("%s(%s)", m.ContainerResource.Name, m.ContainerResource.Container), formatTarget(m.ContainerResource.Target)
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if m.Pods != nil {
+			return m.Pods.Metric.Name, formatTarget(m.Pods.Target)
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if m.Object != nil {
+			return fmt.Sprintf("%s(%s/%s)", m.Object.Metric.Name, m.Object.DescribedObject.Kind, m.Object.DescribedObject.Name), formatTarget(m.Object.Target)
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if m.External != nil {
+			return m.External.Metric.Name, formatTarget(m.External.Target)
+		}
+	}
+	return string(m.Type), "<unknown>"
+}
+
+// describeStatus 当 cm 与 m 为同一指标时返回其当前值
+func describeStatus(m autoscalingv2.MetricSpec, cm autoscalingv2.MetricStatus) (string, bool) {
+	if m.Type != cm.Type {
+		return "", false
+	}
+	switch m.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if m.Resource != nil && cm.Resource != nil && cm.Resource.Name == m.Resource.Name {
+			return formatCurrent(cm.Resource.Current, m.Resource.Target.Type), true
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if m.ContainerResource != nil && cm.ContainerResource != nil &&
+			cm.ContainerResource.Name == m.ContainerResource.Name && cm.ContainerResource.Container == m.ContainerResource.Container {
+			return formatCurrent(cm.ContainerResource.Current, m.ContainerResource.Target.Type), true
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if m.Pods != nil && cm.Pods != nil && cm.Pods.Metric.Name == m.Pods.Metric.Name {
+			return formatCurrent(cm.Pods.Current, m.Pods.Target.Type), true
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if m.Object != nil && cm.Object != nil && cm.Object.Metric.Name == m.Object.Metric.Name &&
+			cm.Object.DescribedObject.Name == m.Object.DescribedObject.Name {
+			return formatCurrent(cm.Object.Current, m.Object.Target.Type), true
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if m.External != nil && cm.External != nil && cm.External.Metric.Name == m.External.Metric.Name {
+			return formatCurrent(cm.External.Current, m.External.Target.Type), true
+		}
+	}
+	return "", false
+}
+
+func formatTarget(t autoscalingv2.MetricTarget) string {
+	switch t.Type {
+	case autoscalingv2.UtilizationMetricType:
+		if t.AverageUtilization != nil {
+			return fmt.Sprintf("%d%%", *t.AverageUtilization)
+		}
+	case autoscalingv2.AverageValueMetricType:
+		return quantity(t.AverageValue)
+	case autoscalingv2.ValueMetricType:
+		return quantity(t.Value)
+	}
+	return "<unknown>"
+}
+
+// formatCurrent 按目标的类型选择当前值的表示方式，保证两侧可比较
+func formatCurrent(c autoscalingv2.MetricValueStatus, targetType autoscalingv2.MetricTargetType) string {
+	switch targetType {
+	case autoscalingv2.UtilizationMetricType:
+		if c.AverageUtilization != nil {
+			return fmt.Sprintf("%d%%", *c.AverageUtilization)
+		}
+	case autoscalingv2.AverageValueMetricType:
+		return quantity(c.AverageValue)
+	case autoscalingv2.ValueMetricType:
+		return quantity(c.Value)
+	}
+	return "<unknown>"
+}
+
+func quantity(q *resource.Quantity) string {
+	if q == nil {
+		return "<unknown>"
+	}
+	return q.String()
+}
@@ -0,0 +1,191 @@
+package hpa
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func quantityPtr(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
+
+func resourceMetric(target int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   corev1.ResourceCPU,
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: int32Ptr(target)},
+		},
+	}
+}
+
+func resourceStatus(current int32) autoscalingv2.MetricStatus {
+	return autoscalingv2.MetricStatus{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricStatus{
+			Name:    corev1.ResourceCPU,
+			Current: autoscalingv2.MetricValueStatus{AverageUtilization: int32Ptr(current), AverageValue: quantityPtr("450m")},
+		},
+	}
+}
+
+func podsMetric() autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: "http_requests"},
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: quantityPtr("100")},
+		},
+	}
+}
+
+func podsStatus() autoscalingv2.MetricStatus {
+	return autoscalingv2.MetricStatus{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricStatus{
+			Metric:  autoscalingv2.MetricIdentifier{Name: "http_requests"},
+			Current: autoscalingv2.MetricValueStatus{AverageValue: quantityPtr("150500m")},
+		},
+	}
+}
+
+func externalMetric() autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: "queue_depth"},
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: quantityPtr("30")},
+		},
+	}
+}
+
+func externalStatus() autoscalingv2.MetricStatus {
+	return autoscalingv2.MetricStatus{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricStatus{
+			Metric:  autoscalingv2.MetricIdentifier{Name: "queue_depth"},
+			Current: autoscalingv2.MetricValueStatus{Value: quantityPtr("12")},
+		},
+	}
+}
+
+func TestFormatTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics []autoscalingv2.MetricSpec
+		current []autoscalingv2.MetricStatus
+		want    string
+	}{
+		{name: "resource 利用率", metrics: []autoscalingv2.MetricSpec{resourceMetric(80)}, current: []autoscalingv2.MetricStatus{resourceStatus(45)}, want: "cpu: 45%/80%"},
+		{name: "pods 平均值", metrics: []autoscalingv2.MetricSpec{podsMetric()}, current: []autoscalingv2.MetricStatus{podsStatus()}, want: "http_requests: 150500m/100"},
+		{name: "external 总量", metrics: []autoscalingv2.MetricSpec{externalMetric()}, current: []autoscalingv2.MetricStatus{externalStatus()}, want: "queue_depth: 12/30"},
+		// 当前值按 spec 的顺序匹配，与 status 中的顺序无关
+		{
+			name:    "多个指标",
+			metrics: []autoscalingv2.MetricSpec{resourceMetric(80), podsMetric(), externalMetric()},
+			current: []autoscalingv2.MetricStatus{externalStatus(), resourceStatus(95), podsStatus()},
+			want:    "cpu: 95%/80%, http_requests: 150500m/100, queue_depth: 12/30",
+		},
+		{
+			name:    "尚无当前值",
+			metrics: []autoscalingv2.MetricSpec{resourceMetric(80), externalMetric()},
+			current: []autoscalingv2.MetricStatus{resourceStatus(45)},
+			want:    "cpu: 45%/80%, queue_depth: <unknown>/30",
+		},
+		{name: "没有指标", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &autoscalingv2.HorizontalPodAutoscaler{
+				Spec:   autoscalingv2.HorizontalPodAutoscalerSpec{Metrics: tt.metrics},
+				Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentMetrics: tt.current},
+			}
+			if got := FormatTargets(h); got != tt.want {
+				t.Errorf("FormatTargets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHPA(t *testing.T) {
+	tests := []struct {
+		name       string
+		min        *int32
+		max        int32
+		current    int32
+		desired    int32
+		conditions []autoscalingv2.HorizontalPodAutoscalerCondition
+		want       Status
+	}{
+		{
+			name: "正常扩缩容", min: int32Ptr(2), max: 10, current: 3, desired: 4,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionFalse},
+			},
+			want: Status{MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 3, DesiredReplicas: 4, AbleToScale: "True", ScalingLimited: "False"},
+		},
+		{
+			name: "达到上限", min: int32Ptr(1), max: 5, current: 5, desired: 5,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.ScalingActive, Status: corev1.ConditionTrue},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionTrue},
+			},
+			want: Status{MinReplicas: 1, MaxReplicas: 5, CurrentReplicas: 5, DesiredReplicas: 5, ScalingLimited: "True", AtMax: true},
+		},
+		// minReplicas 未设置时按 1 处理；未上报 Condition 时为空
+		{
+			name: "默认最小副本数", max: 4, current: 1, desired: 1,
+			want: Status{MinReplicas: 1, MaxReplicas: 4, CurrentReplicas: 1, DesiredReplicas: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					MinReplicas: tt.min,
+					MaxReplicas: tt.max,
+					Metrics:     []autoscalingv2.MetricSpec{resourceMetric(80)},
+				},
+				Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+					CurrentReplicas: tt.current,
+					DesiredReplicas: tt.desired,
+					CurrentMetrics:  []autoscalingv2.MetricStatus{resourceStatus(45)},
+					Conditions:      tt.conditions,
+				},
+			}
+			want := tt.want
+			want.Name, want.Targets = "web", "cpu: 45%/80%"
+			if got := FromHPA(h); got != want {
+				t.Errorf("FromHPA() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web-hpa"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			MaxReplicas:    3,
+		},
+	})
+	got, err := List(context.Background(), clientset, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := got["Deployment/web"]; !ok || s.Name != "web-hpa" || len(got) != 1 {
+		t.Errorf("List() = %+v, want Deployment/web", got)
+	}
+}
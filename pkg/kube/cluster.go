@@ -0,0 +1,53 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+
+	"k8stools/pkg/config"
+)
+
+// SelectClusters 根据 --context/--all-clusters 选出本次要访问的集群
+//   - allClusters 为 true 时返回 clusters 列表中的全部集群
+//   - context 不为空时优先匹配 clusters 中同名（或同 context）的集群，否则使用顶层 kubeconfig 的该 context
+//   - 都未指定时使用顶层 kubeconfig/context
+func SelectClusters(c *config.Config, context string, allClusters bool) ([]config.Cluster, error) {
+	if allClusters {
+		if len(c.Clusters) == 0 {
+			return nil, fmt.Errorf("--all-clusters 需要在配置文件中声明 clusters 列表")
+		}
+		return c.Clusters, nil
+	}
+
+	if context != "" {
+		for _, cl := range c.Clusters {
+			if cl.Name == context || cl.Context == context {
+				return []config.Cluster{cl}, nil
+			}
+		}
+		return []config.Cluster{{Name: context, KubeConfig: c.KubeConfig, Context: context}}, nil
+	}
+
+	return []config.Cluster{{KubeConfig: c.KubeConfig, Context: c.Context}}, nil
+}
+
+// NewClients 为选中的每个集群创建客户端，返回创建成功的客户端以及各集群失败原因合并后的错误；
+// 部分集群失败时其余集群仍可使用，调用方以返回的客户端是否为空判断能否继续
+func NewClients(c *config.Config, context string, allClusters bool) ([]Client, error) {
+	clusters, err := SelectClusters(c, context, allClusters)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]Client, 0, len(clusters))
+	var errs []error
+	for _, cl := range clusters {
+		kc, err := NewClientForCluster(c, cl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("集群 %s: %w", cl.Name, err))
+			continue
+		}
+		clients = append(clients, kc)
+	}
+	return clients, errors.Join(errs...)
+}
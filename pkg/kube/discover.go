@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ListContexts 返回 kubeconfig 中的全部 context（按名称排序）及 current-context
+// kubeconfig 为空时按 $KUBECONFIG、~/.kube/config 的默认规则加载
+func ListContexts(kubeconfig string) ([]string, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	raw, err := loadingRules.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("读取kubeconfig失败: %w", err)
+	}
+
+	contexts := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, raw.CurrentContext, nil
+}
+
+// ListNamespaces 返回集群中全部命名空间的名称（按名称排序）
+func ListNamespaces(ctx context.Context, kc Client) ([]string, error) {
+	list, err := kc.Kubernetes().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取命名空间列表失败: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// IsSystemNamespace 判断是否为 Kubernetes 自带的系统命名空间（kube-*、default）
+func IsSystemNamespace(name string) bool {
+	return name == "default" || strings.HasPrefix(name, "kube-")
+}
@@ -0,0 +1,132 @@
+package kube
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"k8stools/pkg/config"
+)
+
+// Client 各模块访问集群所需的客户端集合，测试时可替换为 fake clientset
+type Client interface {
+	// Name 集群名称，用于报表中的 Cluster 列
+	Name() string
+	// Kubernetes 返回核心 API 客户端
+	Kubernetes() kubernetes.Interface
+	// Metrics 返回 metrics.k8s.io 客户端
+	Metrics() metrics.Interface
+	// RESTConfig 返回底层 rest.Config，exec 等子资源需要使用；fake 客户端返回 nil
+	RESTConfig() *rest.Config
+}
+
+type client struct {
+	name       string
+	kubernetes kubernetes.Interface
+	metrics    metrics.Interface
+	restConfig *rest.Config
+}
+
+func (k *client) Name() string                     { return k.name }
+func (k *client) Kubernetes() kubernetes.Interface { return k.kubernetes }
+func (k *client) Metrics() metrics.Interface       { return k.metrics }
+func (k *client) RESTConfig() *rest.Config         { return k.restConfig }
+
+// NewClient 根据配置文件顶层的 kubeconfig/context 构建客户端，rest.Config、clientset、metrics 客户端只创建一次
+func NewClient(c *config.Config) (Client, error) {
+	return NewClientForCluster(c, config.Cluster{KubeConfig: c.KubeConfig, Context: c.Context})
+}
+
+// NewClientForCluster 为 clusters 列表中的某个集群构建客户端，QPS/Burst 沿用顶层配置
+func NewClientForCluster(c *config.Config, cluster config.Cluster) (Client, error) {
+	kubeconfig := cluster.KubeConfig
+	if kubeconfig == "" {
+		kubeconfig = c.KubeConfig
+	}
+	cfg, err := BuildRESTConfig(kubeconfig, cluster.Context)
+	if err != nil {
+		return nil, err
+	}
+	if c.QPS > 0 {
+		cfg.QPS = c.QPS
+	}
+	if c.Burst > 0 {
+		cfg.Burst = c.Burst
+	}
+
+	return NewClientFromRESTConfig(ClusterName(c, cluster), cfg)
+}
+
+// ClusterName 返回报表中 Cluster 列显示的名称：优先使用 cluster.Name，否则为实际生效的 context 名称
+func ClusterName(c *config.Config, cluster config.Cluster) string {
+	if cluster.Name != "" {
+		return cluster.Name
+	}
+	kubeconfig := cluster.KubeConfig
+	if kubeconfig == "" {
+		kubeconfig = c.KubeConfig
+	}
+	return contextName(kubeconfig, cluster.Context)
+}
+
+// NewClientFromRESTConfig 基于已有 rest.Config 创建客户端
+func NewClientFromRESTConfig(name string, cfg *rest.Config) (Client, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建Kubernetes客户端失败: %w", err)
+	}
+	metricsClient, err := metrics.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建Metrics客户端失败: %w", err)
+	}
+	return NewForClients(name, clientset, metricsClient, cfg), nil
+}
+
+// NewForClients 直接组装客户端，便于注入 fake clientset
+func NewForClients(name string, kubeClient kubernetes.Interface, metricsClient metrics.Interface, cfg *rest.Config) Client {
+	return &client{
+		name:       name,
+		kubernetes: kubeClient,
+		metrics:    metricsClient,
+		restConfig: cfg,
+	}
+}
+
+// BuildRESTConfig 按 kubeconfig 路径和 context 构建 rest.Config
+// kubeconfig 为空时依次尝试 $KUBECONFIG、~/.kube/config，都不可用且未指定 context 时回退到 in-cluster 配置
+func BuildRESTConfig(kubeconfig, context string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		if kubeconfig == "" && context == "" {
+			if inCluster, icErr := rest.InClusterConfig(); icErr == nil {
+				return inCluster, nil
+			}
+		}
+		return nil, fmt.Errorf("构建kubeconfig失败: %w", err)
+	}
+	return cfg, nil
+}
+
+// contextName 返回实际生效的 context 名称，in-cluster 或无法解析时返回 in-cluster
+func contextName(kubeconfig, context string) string {
+	if context != "" {
+		return context
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	raw, err := loadingRules.Load()
+	if err != nil || raw.CurrentContext == "" {
+		return "in-cluster"
+	}
+	return raw.CurrentContext
+}
@@ -0,0 +1,97 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8stools/pkg/config"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: a
+    cluster: {server: "https://a.example.com"}
+  - name: b
+    cluster: {server: "https://b.example.com"}
+users:
+  - name: u
+    user: {}
+contexts:
+  - name: ctx-a
+    context: {cluster: a, user: u}
+  - name: ctx-b
+    context: {cluster: b, user: u}
+current-context: ctx-a
+`
+
+func TestBuildRESTConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// in-cluster 环境变量存在时仍应优先使用 $KUBECONFIG
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	t.Setenv("HOME", dir)
+
+	tests := []struct {
+		name       string
+		env        string
+		kubeconfig string
+		context    string
+		wantHost   string
+		wantErr    bool
+	}{
+		{name: "$KUBECONFIG", env: path, wantHost: "https://a.example.com"},
+		{name: "$KUBECONFIG 指定 context", env: path, context: "ctx-b", wantHost: "https://b.example.com"},
+		{name: "显式路径", kubeconfig: path, wantHost: "https://a.example.com"},
+		{name: "没有 kubeconfig 且不在集群内", env: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "context 不存在", kubeconfig: path, context: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tt.env)
+			cfg, err := BuildRESTConfig(tt.kubeconfig, tt.context)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，得到 host %s", cfg.Host)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Host != tt.wantHost {
+				t.Errorf("host = %s, want %s", cfg.Host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestClusterName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Config{KubeConfig: path}
+	tests := []struct {
+		name    string
+		cluster config.Cluster
+		want    string
+	}{
+		{name: "配置的名称优先", cluster: config.Cluster{Name: "prod", Context: "ctx-b"}, want: "prod"},
+		{name: "指定 context", cluster: config.Cluster{Context: "ctx-b"}, want: "ctx-b"},
+		{name: "顶层 kubeconfig 的 current-context", want: "ctx-a"},
+		{name: "kubeconfig 无法读取", cluster: config.Cluster{KubeConfig: filepath.Join(t.TempDir(), "missing")}, want: "in-cluster"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClusterName(c, tt.cluster); got != tt.want {
+				t.Errorf("ClusterName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8stools/pkg/config"
+)
+
+// NamespaceResolver 将配置中的 namespace（支持 * 与通配符）、namespaceSelector、excludeNamespaces
+// 解析为集群中实际存在的命名空间列表，同一集群同一配置只访问一次 API Server
+type NamespaceResolver struct {
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// NewNamespaceResolver 创建带缓存的命名空间解析器
+func NewNamespaceResolver() *NamespaceResolver {
+	return &NamespaceResolver{cache: make(map[string][]string)}
+}
+
+// Resolve 返回 kc 所在集群中匹配配置的命名空间，需要访问 API Server 时按名称排序
+// namespace 全部为普通名称且未配置 namespaceSelector 时不访问 API Server（kc 可为 nil），直接按 excludeNamespaces 过滤
+func (r *NamespaceResolver) Resolve(ctx context.Context, c *config.Config, kc Client) ([]string, error) {
+	if !NeedsResolve(c) {
+		return filterNamespaces(c.NameSpace, nil, c.ExcludeNamespaces), nil
+	}
+
+	if kc == nil {
+		return nil, fmt.Errorf("namespace 含通配符或配置了 namespaceSelector，需要访问集群解析")
+	}
+
+	key := strings.Join([]string{kc.Name(), strings.Join(c.NameSpace, ","), c.NamespaceSelector, strings.Join(c.ExcludeNamespaces, ",")}, "|")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.cache[key]; ok {
+		return cached, nil
+	}
+
+	list, err := kc.Kubernetes().CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: c.NamespaceSelector})
+	if err != nil {
+		return nil, fmt.Errorf("获取命名空间列表失败: %w", err)
+	}
+	all := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		all = append(all, ns.Name)
+	}
+	sort.Strings(all)
+
+	patterns := c.NameSpace
+	if len(patterns) == 0 {
+		// 只配置了 namespaceSelector
+		patterns = []string{"*"}
+	}
+	resolved := filterNamespaces(all, patterns, c.ExcludeNamespaces)
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("未匹配到任何命名空间（namespace=%v, namespaceSelector=%q, excludeNamespaces=%v）",
+			c.NameSpace, c.NamespaceSelector, c.ExcludeNamespaces)
+	}
+	r.cache[key] = resolved
+	return resolved, nil
+}
+
+// ResolveConfig 返回 namespace 替换为解析结果的配置副本，分析器无需感知通配符与选择器
+func (r *NamespaceResolver) ResolveConfig(ctx context.Context, c *config.Config, kc Client) (*config.Config, error) {
+	namespaces, err := r.Resolve(ctx, c, kc)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *c
+	resolved.NameSpace = namespaces
+	return &resolved, nil
+}
+
+// NeedsResolve 判断配置的命名空间是否需要访问 API Server 解析
+func NeedsResolve(c *config.Config) bool {
+	if c.NamespaceSelector != "" {
+		return true
+	}
+	for _, ns := range c.NameSpace {
+		if IsNamespacePattern(ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNamespacePattern 判断是否为通配符（*、?、[...]）
+func IsNamespacePattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// filterNamespaces 从 names 中选出匹配 patterns（为 nil 时全部保留）且不匹配 excludes 的命名空间，去重并保持原有顺序
+func filterNamespaces(names, patterns, excludes []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		if patterns != nil && !matchAny(name, patterns) {
+			continue
+		}
+		if matchAny(name, excludes) {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+func matchAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package kube
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func namespaceClientset() *fake.Clientset {
+	payments := map[string]string{"team": "payments"}
+	return fake.NewSimpleClientset(
+		namespace("default", nil),
+		namespace("kube-system", nil),
+		namespace("kube-public", nil),
+		namespace("pay-prod", payments),
+		namespace("pay-dev", payments),
+		namespace("shop-prod", map[string]string{"team": "shop"}),
+	)
+}
+
+func TestNamespaceResolverResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.Config
+		want     []string
+		wantErr  string
+		wantList int
+	}{
+		// 普通名称不访问 API Server，也不检查命名空间是否存在
+		{name: "普通名称", config: config.Config{NameSpace: []string{"b", "a", "b", "missing"}}, want: []string{"b", "a", "missing"}},
+		{name: "普通名称排除", config: config.Config{NameSpace: []string{"a", "kube-system"}, ExcludeNamespaces: []string{"kube-*"}}, want: []string{"a"}},
+		{name: "全部", config: config.Config{NameSpace: []string{"*"}}, want: []string{"default", "kube-public", "kube-system", "pay-dev", "pay-prod", "shop-prod"}, wantList: 1},
+		{name: "全部并排除", config: config.Config{NameSpace: []string{"*"}, ExcludeNamespaces: []string{"kube-*", "default"}}, want: []string{"pay-dev", "pay-prod", "shop-prod"}, wantList: 1},
+		{name: "通配符", config: config.Config{NameSpace: []string{"*-prod"}}, want: []string{"pay-prod", "shop-prod"}, wantList: 1},
+		// 通配符与普通名称混用时普通名称也需要存在于集群中
+		{name: "通配符与普通名称", config: config.Config{NameSpace: []string{"default", "pay-?ev", "missing"}}, want: []string{"default", "pay-dev"}, wantList: 1},
+		{name: "标签选择器", config: config.Config{NamespaceSelector: "team=payments"}, want: []string{"pay-dev", "pay-prod"}, wantList: 1},
+		// namespace 与 namespaceSelector 取交集
+		{name: "标签选择器与通配符", config: config.Config{NameSpace: []string{"*-prod"}, NamespaceSelector: "team=payments"}, want: []string{"pay-prod"}, wantList: 1},
+		{name: "标签选择器并排除", config: config.Config{NamespaceSelector: "team", ExcludeNamespaces: []string{"*-dev"}}, want: []string{"pay-prod", "shop-prod"}, wantList: 1},
+		{name: "未匹配", config: config.Config{NameSpace: []string{"*-staging"}}, wantErr: "未匹配到任何命名空间", wantList: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := namespaceClientset()
+			kc := NewForClients("test", clientset, nil, nil)
+			r := NewNamespaceResolver()
+			// 解析两次，第二次应命中缓存
+			for i := 0; i < 2; i++ {
+				got, err := r.Resolve(context.Background(), &tt.config, kc)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("Resolve() = %v, want %v", got, tt.want)
+				}
+			}
+			// 未匹配时不缓存，每次都会重新获取
+			wantList := tt.wantList
+			if tt.wantErr != "" {
+				wantList *= 2
+			}
+			if got := countList(clientset); got != wantList {
+				t.Errorf("list namespaces %d 次, want %d", got, wantList)
+			}
+		})
+	}
+}
+
+func countList(clientset *fake.Clientset) int {
+	n := 0
+	for _, a := range clientset.Actions() {
+		if a.GetVerb() == "list" && a.GetResource().Resource == "namespaces" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNamespaceResolverCache(t *testing.T) {
+	ctx := context.Background()
+	a, b := namespaceClientset(), fake.NewSimpleClientset(namespace("other-prod", nil))
+	kcA, kcB := NewForClients("a", a, nil, nil), NewForClients("b", b, nil, nil)
+	r := NewNamespaceResolver()
+	prod := &config.Config{NameSpace: []string{"*-prod"}}
+
+	if got, _ := r.Resolve(ctx, prod, kcA); !reflect.DeepEqual(got, []string{"pay-prod", "shop-prod"}) {
+		t.Errorf("集群 a = %v", got)
+	}
+	// 缓存按集群区分
+	if got, _ := r.Resolve(ctx, prod, kcB); !reflect.DeepEqual(got, []string{"other-prod"}) {
+		t.Errorf("集群 b = %v", got)
+	}
+	// 缓存按配置区分
+	if got, _ := r.Resolve(ctx, &config.Config{NameSpace: []string{"*-prod"}, ExcludeNamespaces: []string{"shop-*"}}, kcA); !reflect.DeepEqual(got, []string{"pay-prod"}) {
+		t.Errorf("集群 a 排除 shop-* = %v", got)
+	}
+	if got, _ := r.Resolve(ctx, prod, kcA); !reflect.DeepEqual(got, []string{"pay-prod", "shop-prod"}) {
+		t.Errorf("集群 a 再次解析 = %v", got)
+	}
+	if countList(a) != 2 || countList(b) != 1 {
+		t.Errorf("list namespaces a=%d b=%d, want 2 1", countList(a), countList(b))
+	}
+
+	// 获取失败时返回错误，不缓存
+	a.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, context.DeadlineExceeded
+	})
+	if _, err := NewNamespaceResolver().Resolve(ctx, prod, kcA); err == nil || !strings.Contains(err.Error(), "获取命名空间列表失败") {
+		t.Errorf("error = %v, want 获取命名空间列表失败", err)
+	}
+}
+
+func TestNamespaceResolverWithoutClient(t *testing.T) {
+	r := NewNamespaceResolver()
+	got, err := r.Resolve(context.Background(), &config.Config{NameSpace: []string{"demo"}}, nil)
+	if err != nil || !reflect.DeepEqual(got, []string{"demo"}) {
+		t.Errorf("Resolve() = %v, %v, want [demo]", got, err)
+	}
+	if _, err := r.Resolve(context.Background(), &config.Config{NamespaceSelector: "team=payments"}, nil); err == nil {
+		t.Error("namespaceSelector 需要集群客户端")
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	c := &config.Config{NameSpace: []string{"pay-*"}, Prometheus: "http://prometheus:9090"}
+	resolved, err := NewNamespaceResolver().ResolveConfig(context.Background(), c, NewForClients("test", namespaceClientset(), nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resolved.NameSpace, []string{"pay-dev", "pay-prod"}) || resolved.Prometheus != c.Prometheus {
+		t.Errorf("ResolveConfig() = %+v", resolved)
+	}
+	// 不修改原配置
+	if !reflect.DeepEqual(c.NameSpace, []string{"pay-*"}) {
+		t.Errorf("原配置被修改: %v", c.NameSpace)
+	}
+}
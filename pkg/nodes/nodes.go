@@ -0,0 +1,267 @@
+// Package nodes 统计节点及节点池的可分配资源、Requests/Limits 分配量与实际使用量
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sidecar"
+)
+
+// PoolLabels 未配置 nodePoolLabel 时依次尝试的节点池标签
+// 不包含 node.kubernetes.io/instance-type 等机型标签，需要按机型汇总时可将其配置为 nodePoolLabel
+var PoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"karpenter.sh/nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+}
+
+// UnlabeledPool 没有节点池标签的节点归入的节点池名称
+const UnlabeledPool = "(unlabeled)"
+
+// 报表的汇总维度
+const (
+	GroupByNode = "node"
+	GroupByPool = "pool"
+)
+
+// NodeUsage 单个节点或节点池的资源分配情况，CPU 单位为 m，内存单位为 MiB
+// 百分比以 Allocatable 为分母；Limit Overcommit 为 Limits 与 Allocatable 之比，大于 1 表示超卖
+type NodeUsage struct {
+	Cluster            string  `json:"cluster" header:"Cluster"`
+	Pool               string  `json:"pool" header:"Pool"`
+	Node               string  `json:"node,omitempty" header:"Node"`
+	Nodes              int     `json:"nodes" header:"Nodes"`
+	CPUAllocatable     int64   `json:"cpuAllocatable" header:"CPU Allocatable (m)"`
+	CPURequests        int64   `json:"cpuRequests" header:"CPU Requests (m)"`
+	CPULimits          int64   `json:"cpuLimits" header:"CPU Limits (m)"`
+	CPUUsage           int64   `json:"cpuUsage" header:"CPU Usage (m)"`
+	CPURequestPct      float64 `json:"cpuRequestPct" header:"CPU Req (%)" fmt:"%.1f"`
+	CPUUsagePct        float64 `json:"cpuUsagePct" header:"CPU Used (%)" fmt:"%.1f"`
+	CPULimitOvercommit float64 `json:"cpuLimitOvercommit" header:"CPU Limit Overcommit" fmt:"%.2f"`
+	MemAllocatable     int64   `json:"memAllocatable" header:"Mem Allocatable (Mi)"`
+	MemRequests        int64   `json:"memRequests" header:"Mem Requests (Mi)"`
+	MemLimits          int64   `json:"memLimits" header:"Mem Limits (Mi)"`
+	MemUsage           int64   `json:"memUsage" header:"Mem Usage (Mi)"`
+	MemRequestPct      float64 `json:"memRequestPct" header:"Mem Req (%)" fmt:"%.1f"`
+	MemUsagePct        float64 `json:"memUsagePct" header:"Mem Used (%)" fmt:"%.1f"`
+	MemLimitOvercommit float64 `json:"memLimitOvercommit" header:"Mem Limit Overcommit" fmt:"%.2f"`
+	PodsAllocatable    int64   `json:"podsAllocatable" header:"Pods Allocatable"`
+	Pods               int64   `json:"pods" header:"Pods"`
+	PodsPct            float64 `json:"podsPct" header:"Pods (%)" fmt:"%.1f"`
+}
+
+// totals 累加用的原始值，内存单位为字节
+type totals struct {
+	nodes                              int
+	cpuAlloc, cpuReq, cpuLim, cpuUsage int64
+	memAlloc, memReq, memLim, memUsage int64
+	podsAlloc, pods                    int64
+}
+
+// GetNodes 统计单个集群的节点资源，groupBy 为 GroupByNode 或 GroupByPool
+// Requests/Limits 来自调度到节点上的全部未结束 Pod（不受 namespace 配置限制），使用量来自 NodeMetrics；
+// NodeMetrics 不可用时使用量记为 0，并以警告错误返回
+func GetNodes(ctx context.Context, c *config.Config, kc kube.Client, groupBy string) ([]NodeUsage, error) {
+	if groupBy != GroupByNode && groupBy != GroupByPool {
+		return nil, fmt.Errorf("不支持的汇总维度: %s (请使用 %s/%s)", groupBy, GroupByNode, GroupByPool)
+	}
+	clientset := kc.Kubernetes()
+
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Node 失败: %w", err)
+	}
+	podList, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Pod 失败: %w", err)
+	}
+
+	var warning error
+	usage := make(map[string]corev1.ResourceList)
+	nodeMetrics, err := kc.Metrics().MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		warning = fmt.Errorf("获取 NodeMetrics 失败: %w", err)
+	} else {
+		for _, m := range nodeMetrics.Items {
+			usage[m.Name] = m.Usage
+		}
+	}
+
+	// 已结束的 Pod 不再占用节点资源
+	podsByNode := make(map[string][]*corev1.Pod)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	byKey := make(map[string]*totals)
+	pools := make(map[string]string)
+	var keys []string
+	for _, node := range nodeList.Items {
+		pool := PoolOf(&node, c.NodePoolLabel)
+		key := node.Name
+		if groupBy == GroupByPool {
+			key = pool
+		}
+		t, ok := byKey[key]
+		if !ok {
+			t = &totals{}
+			byKey[key] = t
+			pools[key] = pool
+			keys = append(keys, key)
+		}
+
+		t.nodes++
+		t.cpuAlloc += node.Status.Allocatable.Cpu().MilliValue()
+		t.memAlloc += node.Status.Allocatable.Memory().Value()
+		t.podsAlloc += node.Status.Allocatable.Pods().Value()
+		if u, ok := usage[node.Name]; ok {
+			t.cpuUsage += u.Cpu().MilliValue()
+			t.memUsage += u.Memory().Value()
+		}
+		for _, pod := range podsByNode[node.Name] {
+			requests, limits := PodRequestsAndLimits(pod)
+			t.cpuReq += requests.Cpu().MilliValue()
+			t.cpuLim += limits.Cpu().MilliValue()
+			t.memReq += requests.Memory().Value()
+			t.memLim += limits.Memory().Value()
+			t.pods++
+		}
+	}
+	sort.Strings(keys)
+
+	rows := make([]NodeUsage, 0, len(keys))
+	for _, key := range keys {
+		t := byKey[key]
+		row := NodeUsage{
+			Cluster:            kc.Name(),
+			Pool:               pools[key],
+			Nodes:              t.nodes,
+			CPUAllocatable:     t.cpuAlloc,
+			CPURequests:        t.cpuReq,
+			CPULimits:          t.cpuLim,
+			CPUUsage:           t.cpuUsage,
+			CPURequestPct:      percent(t.cpuReq, t.cpuAlloc),
+			CPUUsagePct:        percent(t.cpuUsage, t.cpuAlloc),
+			CPULimitOvercommit: ratio(t.cpuLim, t.cpuAlloc),
+			MemAllocatable:     mebibytes(t.memAlloc),
+			MemRequests:        mebibytes(t.memReq),
+			MemLimits:          mebibytes(t.memLim),
+			MemUsage:           mebibytes(t.memUsage),
+			MemRequestPct:      percent(t.memReq, t.memAlloc),
+			MemUsagePct:        percent(t.memUsage, t.memAlloc),
+			MemLimitOvercommit: ratio(t.memLim, t.memAlloc),
+			PodsAllocatable:    t.podsAlloc,
+			Pods:               t.pods,
+			PodsPct:            percent(t.pods, t.podsAlloc),
+		}
+		if groupBy == GroupByNode {
+			row.Node = key
+		}
+		rows = append(rows, row)
+	}
+	return rows, warning
+}
+
+// PoolOf 返回节点所属的节点池，label 为空时依次尝试 PoolLabels，都没有时返回 UnlabeledPool
+func PoolOf(node *corev1.Node, label string) string {
+	candidates := PoolLabels
+	if label != "" {
+		candidates = []string{label}
+	}
+	for _, l := range candidates {
+		if v := node.Labels[l]; v != "" {
+			return v
+		}
+	}
+	return UnlabeledPool
+}
+
+// PodRequestsAndLimits 按调度器的口径计算 Pod 占用的资源：
+// 常规容器与原生 sidecar 之和，与每个 init 容器运行时的占用（含先于它启动的原生 sidecar）取较大值，再加上 Pod Overhead
+func PodRequestsAndLimits(pod *corev1.Pod) (requests, limits corev1.ResourceList) {
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResources(requests, c.Resources.Requests)
+		addResources(limits, c.Resources.Limits)
+	}
+
+	sidecarRequests, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	initRequests, initLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.InitContainers {
+		if sidecar.IsNativeSidecar(c) {
+			addResources(sidecarRequests, c.Resources.Requests)
+			addResources(sidecarLimits, c.Resources.Limits)
+			addResources(requests, c.Resources.Requests)
+			addResources(limits, c.Resources.Limits)
+			continue
+		}
+		maxResources(initRequests, sumResources(c.Resources.Requests, sidecarRequests))
+		maxResources(initLimits, sumResources(c.Resources.Limits, sidecarLimits))
+	}
+	maxResources(requests, initRequests)
+	maxResources(limits, initLimits)
+
+	addResources(requests, pod.Spec.Overhead)
+	// 未设置 Limits 的资源不计入 Overhead，保持“无限制”语义
+	for name, q := range pod.Spec.Overhead {
+		if v, ok := limits[name]; ok {
+			v.Add(q)
+			limits[name] = v
+		}
+	}
+	return requests, limits
+}
+
+func addResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		v := dst[name]
+		v.Add(q)
+		dst[name] = v
+	}
+}
+
+func sumResources(a, b corev1.ResourceList) corev1.ResourceList {
+	res := corev1.ResourceList{}
+	addResources(res, a)
+	addResources(res, b)
+	return res
+}
+
+func maxResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if v, ok := dst[name]; !ok || q.Cmp(v) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+func mebibytes(bytes int64) int64 {
+	return bytes / (1024 * 1024)
+}
+
+// percent 占 Allocatable 的百分比，Allocatable 为 0 时返回 0
+func percent(used, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 0
+	}
+	return float64(used) / float64(allocatable) * 100
+}
+
+func ratio(used, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 0
+	}
+	return float64(used) / float64(allocatable)
+}
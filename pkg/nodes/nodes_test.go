@@ -0,0 +1,143 @@
+package nodes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// container 构造容器，cpu/mem 为空表示不设置该项
+func container(name, cpuReq, memReq, cpuLim, memLim string) corev1.Container {
+	c := corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}}
+	set := func(list corev1.ResourceList, name corev1.ResourceName, v string) {
+		if v != "" {
+			list[name] = resource.MustParse(v)
+		}
+	}
+	set(c.Resources.Requests, corev1.ResourceCPU, cpuReq)
+	set(c.Resources.Requests, corev1.ResourceMemory, memReq)
+	set(c.Resources.Limits, corev1.ResourceCPU, cpuLim)
+	set(c.Resources.Limits, corev1.ResourceMemory, memLim)
+	return c
+}
+
+func nativeSidecar(c corev1.Container) corev1.Container {
+	always := corev1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+	return c
+}
+
+func TestPodRequestsAndLimits(t *testing.T) {
+	tests := []struct {
+		name                   string
+		spec                   corev1.PodSpec
+		cpuReq, memReq         string
+		cpuLim, memLim         string
+		noCPULimit, noMemLimit bool
+	}{
+		{
+			name: "常规容器求和",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				container("app", "100m", "128Mi", "200m", "256Mi"),
+				container("proxy", "50m", "64Mi", "100m", "128Mi"),
+			}},
+			cpuReq: "150m", memReq: "192Mi", cpuLim: "300m", memLim: "384Mi",
+		},
+		{
+			name: "init 容器更大时取 init 容器",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("migrate", "500m", "64Mi", "1", "128Mi")},
+				Containers:     []corev1.Container{container("app", "100m", "128Mi", "200m", "256Mi")},
+			},
+			cpuReq: "500m", memReq: "128Mi", cpuLim: "1", memLim: "256Mi",
+		},
+		{
+			name: "原生 sidecar 计入常规容器，并叠加到其后的 init 容器",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					nativeSidecar(container("mesh", "100m", "64Mi", "200m", "128Mi")),
+					container("migrate", "300m", "32Mi", "400m", "64Mi"),
+				},
+				Containers: []corev1.Container{container("app", "100m", "128Mi", "200m", "256Mi")},
+			},
+			// 常规容器 + sidecar = 200m/192Mi，init 运行时 = 400m/96Mi
+			cpuReq: "400m", memReq: "192Mi", cpuLim: "600m", memLim: "384Mi",
+		},
+		{
+			name: "Overhead 只加到已设置 Limits 的资源",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("app", "100m", "128Mi", "200m", "")},
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
+				},
+			},
+			cpuReq: "110m", memReq: "144Mi", cpuLim: "210m", noMemLimit: true,
+		},
+		{
+			name:       "未设置资源",
+			spec:       corev1.PodSpec{Containers: []corev1.Container{container("app", "", "", "", "")}},
+			noCPULimit: true, noMemLimit: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, limits := PodRequestsAndLimits(&corev1.Pod{Spec: tt.spec})
+			check := func(what string, list corev1.ResourceList, name corev1.ResourceName, want string, absent bool) {
+				t.Helper()
+				got, ok := list[name]
+				if absent {
+					if ok {
+						t.Errorf("%s %s = %s, want unset", what, name, got.String())
+					}
+					return
+				}
+				w := resource.MustParse("0")
+				if want != "" {
+					w = resource.MustParse(want)
+				}
+				if got.Cmp(w) != 0 {
+					t.Errorf("%s %s = %s, want %s", what, name, got.String(), w.String())
+				}
+			}
+			check("requests", requests, corev1.ResourceCPU, tt.cpuReq, false)
+			check("requests", requests, corev1.ResourceMemory, tt.memReq, false)
+			check("limits", limits, corev1.ResourceCPU, tt.cpuLim, tt.noCPULimit)
+			check("limits", limits, corev1.ResourceMemory, tt.memLim, tt.noMemLimit)
+		})
+	}
+}
+
+func TestPoolOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		label  string
+		want   string
+	}{
+		{name: "GKE", labels: map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}, want: "default-pool"},
+		{name: "Karpenter", labels: map[string]string{"karpenter.sh/nodepool": "spot"}, want: "spot"},
+		// 按 PoolLabels 的顺序取第一个存在的标签
+		{name: "多个节点池标签", labels: map[string]string{"karpenter.sh/nodepool": "spot", "eks.amazonaws.com/nodegroup": "ng-1"}, want: "ng-1"},
+		// 机型不是节点池，没有节点池标签的节点归入 UnlabeledPool
+		{name: "只有机型标签", labels: map[string]string{"node.kubernetes.io/instance-type": "m5.large"}, want: UnlabeledPool},
+		{name: "没有标签", want: UnlabeledPool},
+		{name: "配置的标签", labels: map[string]string{"node-group": "batch", "cloud.google.com/gke-nodepool": "default-pool"}, label: "node-group", want: "batch"},
+		// 配置标签后不再尝试 PoolLabels
+		{name: "缺少配置的标签", labels: map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}, label: "node-group", want: UnlabeledPool},
+		{name: "按机型汇总", labels: map[string]string{"node.kubernetes.io/instance-type": "m5.large"}, label: "node.kubernetes.io/instance-type", want: "m5.large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}
+			if got := PoolOf(node, tt.label); got != tt.want {
+				t.Errorf("PoolOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
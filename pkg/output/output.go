@@ -4,39 +4,240 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
+	"sigs.k8s.io/yaml"
 )
 
-// OutputData 输出通用数据（支持 csv/json/table）
+// 支持的输出格式
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+)
+
+// Formats 全部支持的输出格式，用于命令行帮助和参数校验
+var Formats = []string{FormatTable, FormatJSON, FormatCSV, FormatYAML, FormatMarkdown}
+
+// Options 输出参数，对应全局的 -o/--out-file/--out-dir
+type Options struct {
+	Format  string // 输出格式，为空时使用 table
+	OutFile string // 输出文件路径，优先级高于 OutDir
+	OutDir  string // 输出目录，文件名为 <报表名>.<扩展名>
+}
+
+// Render 将结构体切片按指定格式输出
+// 行结构体中带 header tag 的字段作为表格列，可用 fmt tag 指定数值格式（如 fmt:"%.4f"）；
+// json/yaml 直接序列化结构体，保留数值类型。name 为报表名，输出到目录时作为文件名。
+func Render(rows interface{}, name string, opts Options) error {
+	format := opts.Format
+	if format == "" {
+		format = FormatTable
+	}
+	if !isSupported(format) {
+		return fmt.Errorf("不支持的输出格式: %s (请使用 %s)", format, strings.Join(Formats, "/"))
+	}
+
+	target := opts.OutFile
+	if target == "" && opts.OutDir != "" {
+		if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+			return fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		target = filepath.Join(opts.OutDir, name+"."+extension(format))
+	}
+
+	var w io.Writer = os.Stdout
+	if target != "" {
+		file, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("创建输出文件失败: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := write(w, rows, format); err != nil {
+		return err
+	}
+	if target != "" {
+		fmt.Fprintf(os.Stderr, "✅ 已生成 %s\n", target)
+	}
+	return nil
+}
+
+// OutputData 输出通用数据（支持 csv/json/table/yaml/markdown）
 func OutputData(headers []string, rows [][]string, format string) {
+	var err error
 	switch format {
-	case "table":
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader(headers)
-		table.AppendBulk(rows)
-		table.Render()
-	case "json":
-		var jsonList []map[string]string
+	case FormatJSON, FormatYAML:
+		var list []map[string]string
 		for _, row := range rows {
 			entry := make(map[string]string)
 			for i := range headers {
 				entry[headers[i]] = row[i]
 			}
-			jsonList = append(jsonList, entry)
+			list = append(list, entry)
+		}
+		err = encode(os.Stdout, list, format)
+	case FormatTable, FormatCSV, FormatMarkdown:
+		err = writeTable(os.Stdout, headers, rows, format)
+	default:
+		err = fmt.Errorf("不支持的输出格式: %s (请使用 %s)", format, strings.Join(Formats, "/"))
+	}
+	if err != nil {
+		fmt.Println("❌ 输出失败:", err)
+	}
+}
+
+func write(w io.Writer, rows interface{}, format string) error {
+	switch format {
+	case FormatJSON, FormatYAML:
+		v := reflect.ValueOf(rows)
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			// 保证空结果输出 [] 而不是 null
+			rows = []struct{}{}
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(jsonList); err != nil {
-			fmt.Println("❌ JSON 输出失败:", err)
+		return encode(w, rows, format)
+	default:
+		headers, table, err := flatten(rows)
+		if err != nil {
+			return err
 		}
-	case "csv":
-		writer := csv.NewWriter(os.Stdout)
+		return writeTable(w, headers, table, format)
+	}
+}
+
+func encode(w io.Writer, v interface{}, format string) error {
+	if format == FormatYAML {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("YAML 输出失败: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("JSON 输出失败: %w", err)
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, headers []string, rows [][]string, format string) error {
+	switch format {
+	case FormatTable:
+		table := tablewriter.NewWriter(w)
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		table.SetHeader(headers)
+		table.AppendBulk(rows)
+		table.Render()
+		return nil
+	case FormatCSV:
+		writer := csv.NewWriter(w)
 		writer.Write(headers)
 		writer.WriteAll(rows)
 		writer.Flush()
+		return writer.Error()
+	case FormatMarkdown:
+		var b strings.Builder
+		b.WriteString("| " + strings.Join(escapeMarkdown(headers), " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+		for _, row := range rows {
+			b.WriteString("| " + strings.Join(escapeMarkdown(row), " | ") + " |\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("不支持的输出格式: %s", format)
+}
+
+// flatten 通过 header tag 把结构体切片转换成表头和字符串行
+func flatten(rows interface{}) ([]string, [][]string, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("输出数据必须是切片，实际为 %T", rows)
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("输出数据必须是结构体切片，实际为 %T", rows)
+	}
+
+	var headers []string
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		if h := t.Field(i).Tag.Get("header"); h != "" && h != "-" {
+			headers = append(headers, h)
+			fields = append(fields, i)
+		}
+	}
+
+	table := make([][]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		row := make([]string, 0, len(fields))
+		for _, idx := range fields {
+			row = append(row, formatValue(item.Field(idx), t.Field(idx).Tag.Get("fmt")))
+		}
+		table = append(table, row)
+	}
+	return headers, table, nil
+}
+
+func formatValue(v reflect.Value, format string) string {
+	if format != "" {
+		return fmt.Sprintf(format, v.Interface())
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%.2f", v.Float())
+	case reflect.Slice:
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return strings.Join(parts, ",")
 	default:
-		fmt.Printf("❌ 不支持的输出格式: %s (请使用 csv/json/table)\n", format)
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func escapeMarkdown(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "|", `\|`)
+		out[i] = strings.ReplaceAll(c, "\n", "<br>")
+	}
+	return out
+}
+
+func extension(format string) string {
+	switch format {
+	case FormatTable:
+		return "txt"
+	case FormatMarkdown:
+		return "md"
+	default:
+		return format
+	}
+}
+
+func isSupported(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
 	}
+	return false
 }
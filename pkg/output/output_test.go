@@ -0,0 +1,129 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testRow struct {
+	Name   string   `json:"name" header:"Name"`
+	CPU    int64    `json:"cpu" header:"CPU (m)"`
+	Ratio  float64  `json:"ratio" header:"Ratio" fmt:"%.1f"`
+	Tags   []string `json:"tags" header:"Tags"`
+	Note   string   `json:"note" header:"说明"`
+	Hidden string   `json:"hidden"`
+}
+
+var testRows = []testRow{
+	{Name: "web", CPU: 250, Ratio: 0.456, Tags: []string{"a", "b"}, Note: "x|y"},
+	{Name: "db", CPU: 1000, Ratio: 12, Note: "line1\nline2"},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		rows   interface{}
+		want   string
+	}{
+		{
+			format: FormatTable,
+			rows:   testRows,
+			want: `+------+---------+-------+------+-------+
+| Name | CPU (m) | Ratio | Tags | 说明  |
++------+---------+-------+------+-------+
+| web  |     250 |   0.5 | a,b  | x|y   |
+| db   |    1000 |  12.0 |      | line1 |
+|      |         |       |      | line2 |
++------+---------+-------+------+-------+
+`,
+		},
+		{
+			format: FormatCSV,
+			rows:   testRows,
+			want:   "Name,CPU (m),Ratio,Tags,说明\nweb,250,0.5,\"a,b\",x|y\ndb,1000,12.0,,\"line1\nline2\"\n",
+		},
+		{
+			format: FormatMarkdown,
+			rows:   testRows,
+			want: `| Name | CPU (m) | Ratio | Tags | 说明 |
+| --- | --- | --- | --- | --- |
+| web | 250 | 0.5 | a,b | x\|y |
+| db | 1000 | 12.0 |  | line1<br>line2 |
+`,
+		},
+		{
+			format: FormatJSON,
+			rows:   testRows[:1],
+			want: `[
+  {
+    "name": "web",
+    "cpu": 250,
+    "ratio": 0.456,
+    "tags": [
+      "a",
+      "b"
+    ],
+    "note": "x|y",
+    "hidden": ""
+  }
+]
+`,
+		},
+		{
+			format: FormatYAML,
+			rows:   testRows[:1],
+			want: `- cpu: 250
+  hidden: ""
+  name: web
+  note: x|y
+  ratio: 0.456
+  tags:
+  - a
+  - b
+`,
+		},
+		// 空结果输出 [] 而不是 null
+		{format: FormatJSON, rows: []testRow(nil), want: "[]\n"},
+		{format: FormatYAML, rows: []testRow(nil), want: "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			if err := Render(tt.rows, "report", Options{Format: tt.format, OutFile: out}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Render(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOutDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	for _, format := range Formats {
+		if err := Render(testRows, "cpu", Options{Format: format, OutDir: dir}); err != nil {
+			t.Fatalf("Render(%s): %v", format, err)
+		}
+	}
+	for _, name := range []string{"cpu.txt", "cpu.json", "cpu.csv", "cpu.yaml", "cpu.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("缺少输出文件 %s: %v", name, err)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	if err := Render(testRows, "report", Options{Format: "xml", OutFile: out}); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+	if err := Render([]string{"a"}, "report", Options{Format: FormatCSV, OutFile: out}); err == nil {
+		t.Error("非结构体切片应返回错误")
+	}
+}
@@ -0,0 +1,257 @@
+// Package paradise 根据 Pod 实际使用量给出理想的 Requests/Limits 建议
+package paradise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/quota"
+	"k8stools/pkg/recommend"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/workload"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceAdvice 单个容器的资源建议，使用量统计汇总工作负载下同名容器在采样窗口（或 Prometheus 历史窗口）内的全部读数，
+// Samples 为参与统计的读数个数
+type ResourceAdvice struct {
+	Cluster    string `json:"cluster" header:"Cluster"`
+	Namespace  string `json:"namespace" header:"Namespace"`
+	Kind       string `json:"kind" header:"Kind"`
+	Workload   string `json:"workload" header:"Workload"`
+	Container  string `json:"container" header:"Container"`
+	Samples    int    `json:"samples" header:"Samples"`
+	CPUMin     int64  `json:"cpuMin" header:"CPU Min (m)"`
+	CPUAvg     int64  `json:"cpuAvg" header:"CPU Avg (m)"`
+	CPUP95     int64  `json:"cpuP95" header:"CPU P95 (m)"`
+	CPUMax     int64  `json:"cpuMax" header:"CPU Max (m)"`
+	MemMin     int64  `json:"memMin" header:"Mem Min (Mi)"`
+	MemAvg     int64  `json:"memAvg" header:"Mem Avg (Mi)"`
+	MemP95     int64  `json:"memP95" header:"Mem P95 (Mi)"`
+	MemMax     int64  `json:"memMax" header:"Mem Max (Mi)"`
+	CPURequest int64  `json:"cpuRequest" header:"建议 CPU Requests (m)"`
+	CPULimit   int64  `json:"cpuLimit" header:"建议 CPU Limits (m)"`
+	MemRequest int64  `json:"memRequest" header:"建议 Memory Requests (Mi)"`
+	MemLimit   int64  `json:"memLimit" header:"建议 Memory Limits (Mi)"`
+	Advice     string `json:"advice" header:"建议说明"`
+	Policy     string `json:"policy" header:"策略"`
+	Basis      string `json:"basis" header:"计算依据"`
+	// Compliance 按命名空间 LimitRange 修正建议值或预计超出 ResourceQuota 的说明
+	Compliance string `json:"compliance" header:"合规说明"`
+}
+
+// GetParadise 为单个集群内配置的命名空间下每个工作负载（见 workload.Kinds）的容器生成资源建议
+// 使用量来自 metrics-server（按 opts 采样）或 Prometheus 历史数据（paradise.source），建议值按 recommend 中匹配的策略计算，
+// 未配置时按 paradise.policy 计算；建议值再按命名空间的 LimitRange 修正，并检查是否超出 ResourceQuota（见 quota.Constraints.Enforce）；
+// 个别命名空间失败时返回已生成的建议以及合并后的错误
+func GetParadise(ctx context.Context, c *config.Config, kc kube.Client, opts sampling.Options) ([]ResourceAdvice, error) {
+	cfg := *c
+	config.ApplyDefaults(&cfg)
+	source := cfg.Paradise.Source
+	policy := cfg.Paradise.Policy
+	selector, err := recommend.NewSelector(cfg.Recommend)
+	if err != nil {
+		return nil, fmt.Errorf("recommend 策略配置错误: %w", err)
+	}
+	clientset := kc.Kubernetes()
+
+	var rows []ResourceAdvice
+	var errs []error
+
+	var samples map[string][]sampling.Sample
+	var window time.Duration
+	if source == config.ParadiseSourcePrometheus {
+		d, err := model.ParseDuration(cfg.Paradise.Window)
+		if err != nil {
+			return nil, fmt.Errorf("paradise.window 格式错误: %w", err)
+		}
+		window = time.Duration(d)
+	} else {
+		var err error
+		samples, err = sampling.Collect(ctx, kc.Metrics(), c.NameSpace, opts)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, ns := range c.NameSpace {
+		// 个别类型的工作负载获取失败时仍为其余类型生成建议
+		workloads, owners, err := workload.List(ctx, clientset, ns)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+		}
+
+		podList, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("无法获取命名空间 %s 的 Pods: %w", ns, err))
+			continue
+		}
+		podsByOwner := owners.Group(podList.Items)
+
+		// 工作负载 Key -> 容器名 -> 使用量读数
+		var usage map[string]map[string]*series
+		if source == config.ParadiseSourcePrometheus {
+			history, err := loadHistory(ctx, c.Prometheus, ns, window)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+				continue
+			}
+			usage = groupHistory(history, workloads, podsByOwner)
+		} else {
+			// 没有任何一轮采样成功时无法给出建议，错误已由 sampling.Collect 返回
+			if len(samples[ns]) == 0 {
+				continue
+			}
+			usage = groupSamples(samples[ns], workloads, podsByOwner)
+		}
+
+		start := len(rows)
+		for _, w := range workloads {
+			containers := usage[w.Key()]
+
+			// map 遍历顺序不固定，按容器名排序保证输出稳定
+			cnames := make([]string, 0, len(containers))
+			for cname := range containers {
+				cnames = append(cnames, cname)
+			}
+			sort.Strings(cnames)
+
+			for _, cname := range cnames {
+				s := containers[cname]
+				if len(s.cpu) == 0 && len(s.mem) == 0 {
+					continue
+				}
+				cpuStats := sampling.Summarize(s.cpu)
+				memStats := sampling.Summarize(s.mem)
+				a := advise(policy, s)
+				if r, ok := selector.Lookup(ns, w.Name); ok {
+					a = fromRecommendation(r.Recommend(s.usage()))
+				}
+				rows = append(rows, ResourceAdvice{
+					Cluster:    kc.Name(),
+					Namespace:  ns,
+					Kind:       w.Kind,
+					Workload:   w.Name,
+					Container:  cname,
+					Samples:    max(len(s.cpu), len(s.mem)),
+					CPUMin:     cpuStats.Min,
+					CPUAvg:     cpuStats.Avg,
+					CPUP95:     cpuStats.P95,
+					CPUMax:     cpuStats.Max,
+					MemMin:     memStats.Min,
+					MemAvg:     memStats.Avg,
+					MemP95:     memStats.P95,
+					MemMax:     memStats.Max,
+					CPURequest: a.cpuRequest,
+					CPULimit:   a.cpuLimit,
+					MemRequest: a.memRequest,
+					MemLimit:   a.memLimit,
+					Advice:     a.text,
+					Policy:     a.policy,
+					Basis:      a.basis,
+				})
+			}
+		}
+
+		cons, err := quota.Load(ctx, clientset, ns)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		enforce(rows[start:], cons, workloads)
+	}
+
+	return rows, errors.Join(errs...)
+}
+
+// enforce 按 LimitRange 与 ResourceQuota 校验同一命名空间下的建议，修正后的值与说明写回 rows
+func enforce(rows []ResourceAdvice, cons *quota.Constraints, workloads []workload.Workload) {
+	items := make([]quota.Item, len(rows))
+	for i, r := range rows {
+		items[i] = quota.Item{
+			Kind:       r.Kind,
+			Workload:   r.Workload,
+			Container:  r.Container,
+			CPURequest: r.CPURequest,
+			CPULimit:   r.CPULimit,
+			MemRequest: r.MemRequest,
+			MemLimit:   r.MemLimit,
+		}
+	}
+	cons.Enforce(items, workloads)
+	for i := range rows {
+		it := &items[i]
+		rows[i].CPURequest, rows[i].CPULimit = it.CPURequest, it.CPULimit
+		rows[i].MemRequest, rows[i].MemLimit = it.MemRequest, it.MemLimit
+		rows[i].Compliance = it.Reason()
+	}
+}
+
+// groupSamples 将 metrics-server 各轮采样按工作负载与容器名汇总
+func groupSamples(samples []sampling.Sample, workloads []workload.Workload, podsByOwner map[string][]corev1.Pod) map[string]map[string]*series {
+	usage := make(map[string]map[string]*series)
+	for _, w := range workloads {
+		containers := make(map[string]*series)
+		for _, sample := range samples {
+			for _, pod := range podsByOwner[w.Key()] {
+				for cname, u := range sample[pod.Name] {
+					s := containers[cname]
+					if s == nil {
+						s = &series{}
+						containers[cname] = s
+					}
+					s.cpu = append(s.cpu, u.CPU)
+					s.mem = append(s.mem, u.Memory/(1024*1024))
+				}
+			}
+		}
+		usage[w.Key()] = containers
+	}
+	return usage
+}
+
+// groupHistory 将 Prometheus 历史数据按工作负载与容器名汇总
+// 现存 Pod 按 ownerReferences 归属，窗口内已删除的 Pod 按名称推测（见 guessOwner）
+func groupHistory(history map[string]map[string]*series, workloads []workload.Workload, podsByOwner map[string][]corev1.Pod) map[string]map[string]*series {
+	podOwner := make(map[string]string)
+	for key, pods := range podsByOwner {
+		for _, pod := range pods {
+			podOwner[pod.Name] = key
+		}
+	}
+	byName := make(map[string]string, len(workloads))
+	for _, w := range workloads {
+		if _, ok := byName[w.Name]; !ok {
+			byName[w.Name] = w.Key()
+		}
+	}
+
+	usage := make(map[string]map[string]*series)
+	for pod, containers := range history {
+		key, ok := podOwner[pod]
+		if !ok {
+			if key, ok = guessOwner(pod, byName); !ok {
+				continue
+			}
+		}
+		if usage[key] == nil {
+			usage[key] = make(map[string]*series)
+		}
+		for cname, s := range containers {
+			merged := usage[key][cname]
+			if merged == nil {
+				merged = &series{}
+				usage[key][cname] = merged
+			}
+			merged.cpu = append(merged.cpu, s.cpu...)
+			merged.mem = append(merged.mem, s.mem...)
+		}
+	}
+	return usage
+}
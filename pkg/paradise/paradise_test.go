@@ -0,0 +1,266 @@
+package paradise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/sampling"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func defaultPolicy() config.ParadisePolicy {
+	c := &config.Config{}
+	config.ApplyDefaults(c)
+	return c.Paradise.Policy
+}
+
+// legacyAdvise 早期版本按平均值硬编码的规则，默认策略需与之一致
+func legacyAdvise(avgCPU, avgMem int64) (cpuRequest, cpuLimit, memRequest, memLimit int64, text string) {
+	switch {
+	case avgCPU < 50:
+		cpuRequest, cpuLimit = 50, 100
+		text = "使用率较低，建议使用最小推荐值"
+	case avgCPU > 1000:
+		cpuRequest, cpuLimit = avgCPU/2, avgCPU
+		text = "使用率较高，建议设置严格限制"
+	default:
+		cpuRequest, cpuLimit = avgCPU/2, avgCPU
+		text = "正常使用，建议标准配置"
+	}
+	switch {
+	case avgMem < 64:
+		memRequest, memLimit = 64, 128
+	case avgMem > 1024:
+		memRequest, memLimit = int64(float64(avgMem)*0.75), int64(float64(avgMem)*1.5)
+	default:
+		memRequest, memLimit = int64(float64(avgMem)*0.8), int64(float64(avgMem)*1.5)
+	}
+	return
+}
+
+func TestAdviseDefaultPolicyMatchesLegacy(t *testing.T) {
+	policy := defaultPolicy()
+	// 覆盖各阈值两侧
+	cpus := []int64{0, 1, 49, 50, 51, 333, 999, 1000, 1001, 4321}
+	mems := []int64{0, 1, 63, 64, 65, 500, 1023, 1024, 1025, 3001}
+	for _, cpu := range cpus {
+		for _, mem := range mems {
+			// 平均值向下取整：cpu、cpu+1 与 mem、mem+1 各一个读数时平均值仍为 cpu、mem
+			s := &series{cpu: []int64{cpu, cpu + 1}, mem: []int64{mem, mem + 1}}
+			got := advise(policy, s)
+			cr, cl, mr, ml, text := legacyAdvise(cpu, mem)
+			if got.cpuRequest != cr || got.cpuLimit != cl || got.memRequest != mr || got.memLimit != ml || got.text != text {
+				t.Errorf("cpu=%d mem=%d: got %d/%d %d/%d %q, want %d/%d %d/%d %q", cpu, mem,
+					got.cpuRequest, got.cpuLimit, got.memRequest, got.memLimit, got.text, cr, cl, mr, ml, text)
+			}
+			if got.policy != builtinPolicy {
+				t.Errorf("policy = %s, want %s", got.policy, builtinPolicy)
+			}
+		}
+	}
+}
+
+func TestAdvisePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func(p *config.ParadisePolicy)
+		series series
+		want   [4]int64
+		text   string
+		basis  string
+	}{
+		{
+			name:   "默认策略",
+			series: series{cpu: []int64{200, 400}, mem: []int64{200, 400}},
+			want:   [4]int64{150, 300, 240, 450},
+			text:   "正常使用，建议标准配置",
+			basis:  "cpuRequest=avg * 0.50(=150m); cpuLimit=avg * 1.00(=300m); memRequest=avg * 0.80(=240Mi); memLimit=avg * 1.50(=450Mi)",
+		},
+		{
+			name:   "低于阈值取最小推荐值",
+			series: series{cpu: []int64{10}, mem: []int64{10}},
+			want:   [4]int64{50, 100, 64, 128},
+			text:   "使用率较低，建议使用最小推荐值",
+			basis:  "cpuRequest=avg * 0.50(=50m); cpuLimit=avg * 1.00(=100m); memRequest=avg * 0.80(=64Mi); memLimit=avg * 1.50(=128Mi); 低于 lowCpu/lowMem 时取最小推荐值",
+		},
+		{
+			name:   "高内存系数",
+			series: series{cpu: []int64{2000}, mem: []int64{2048}},
+			want:   [4]int64{1000, 2000, 1536, 3072},
+			text:   "使用率较高，建议设置严格限制",
+			basis:  "cpuRequest=avg * 0.50(=1000m); cpuLimit=avg * 1.00(=2000m); memRequest=avg * 0.75(=1536Mi); memLimit=avg * 1.50(=3072Mi)",
+		},
+		{
+			// p50=200、p95=1000，Limits 加 20% 余量
+			name: "分位数与余量",
+			policy: func(p *config.ParadisePolicy) {
+				p.CPURequestPercentile, p.CPULimitPercentile = 50, 95
+				p.MemRequestPercentile, p.MemLimitPercentile = 50, 100
+				p.CPURequestFactor, p.CPUHeadroom, p.MemHeadroom = 1, 0.2, 0.5
+			},
+			series: series{cpu: []int64{1000, 100, 200, 300}, mem: []int64{256, 512, 128, 1024}},
+			want:   [4]int64{200, 1200, 204, 2304},
+			text:   "正常使用，建议标准配置",
+			basis:  "cpuRequest=p50 * 1.00(=200m); cpuLimit=p95 * 1.20(=1200m); memRequest=p50 * 0.80(=204Mi); memLimit=p100 * 2.25(=2304Mi)",
+		},
+		{
+			name:   "自定义阈值",
+			policy: func(p *config.ParadisePolicy) { p.LowCPU, p.MinCPURequest, p.MinCPULimit = 500, 250, 500 },
+			series: series{cpu: []int64{400}, mem: []int64{100}},
+			want:   [4]int64{250, 500, 80, 150},
+			text:   "使用率较低，建议使用最小推荐值",
+			basis:  "cpuRequest=avg * 0.50(=250m); cpuLimit=avg * 1.00(=500m); memRequest=avg * 0.80(=80Mi); memLimit=avg * 1.50(=150Mi); 低于 lowCpu/lowMem 时取最小推荐值",
+		},
+		{
+			// Limits 基准低于 Requests 基准时不低于 Requests
+			name: "Limits 不低于 Requests",
+			policy: func(p *config.ParadisePolicy) {
+				p.CPURequestPercentile, p.CPURequestFactor, p.CPULimitFactor = 100, 1, 0.5
+			},
+			series: series{cpu: []int64{100, 900}, mem: []int64{100}},
+			want:   [4]int64{900, 900, 80, 150},
+			text:   "正常使用，建议标准配置",
+			basis:  "cpuRequest=p100 * 1.00(=900m); cpuLimit=avg * 0.50(=900m); memRequest=avg * 0.80(=80Mi); memLimit=avg * 1.50(=150Mi)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{}
+			if tt.policy != nil {
+				tt.policy(&c.Paradise.Policy)
+			}
+			config.ApplyDefaults(c)
+			a := advise(c.Paradise.Policy, &tt.series)
+			if got := [4]int64{a.cpuRequest, a.cpuLimit, a.memRequest, a.memLimit}; got != tt.want {
+				t.Errorf("advise() = %v, want %v", got, tt.want)
+			}
+			if a.text != tt.text {
+				t.Errorf("text = %q, want %q", a.text, tt.text)
+			}
+			if a.basis != tt.basis {
+				t.Errorf("basis = %q\nwant %q", a.basis, tt.basis)
+			}
+		})
+	}
+}
+
+// prometheusServer 模拟 query_range 接口，按查询的指标返回对应的序列，values 为各 Pod 容器 app 的读数
+func prometheusServer(t *testing.T, cpu, mem map[string][]float64) *httptest.Server {
+	t.Helper()
+	matrix := func(values map[string][]float64) []interface{} {
+		var result []interface{}
+		for pod, vs := range values {
+			var points [][]interface{}
+			for i, v := range vs {
+				points = append(points, []interface{}{1700000000 + i*300, fmt.Sprint(v)})
+			}
+			result = append(result, map[string]interface{}{
+				"metric": map[string]string{"pod": pod, "container": "app"},
+				"values": points,
+			})
+		}
+		return result
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.FormValue("query")
+		if r.URL.Path != "/api/v1/query_range" || !strings.Contains(query, `namespace="demo"`) {
+			t.Errorf("unexpected request %s %s", r.URL.Path, query)
+		}
+		values := mem
+		if strings.Contains(query, "container_cpu_usage_seconds_total") {
+			values = cpu
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "matrix", "result": matrix(values)},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetParadisePrometheus(t *testing.T) {
+	isController := true
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "db", UID: "sts-db"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "demo", Name: "db-0",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", UID: "sts-db", Controller: &isController}},
+		}},
+	)
+	const mi = 1024 * 1024
+	// db-1 已被删除，按名称归入 db；orphan-x 找不到工作负载，忽略
+	srv := prometheusServer(t,
+		map[string][]float64{"db-0": {0.125, 0.25}, "db-1": {0.5, 1}, "orphan-x": {8}},
+		map[string][]float64{"db-0": {256 * mi, 512 * mi}, "db-1": {768 * mi, 1024 * mi}, "orphan-x": {8192 * mi}},
+	)
+
+	c := &config.Config{
+		NameSpace:  []string{"demo"},
+		Prometheus: srv.URL,
+		Paradise: config.ParadiseConfig{
+			Source: config.ParadiseSourcePrometheus,
+			Window: "1d",
+			Policy: config.ParadisePolicy{
+				CPURequestPercentile: 50, CPULimitPercentile: 95,
+				MemLimitPercentile: 100, CPUHeadroom: 0.2,
+			},
+		},
+	}
+	rows, err := GetParadise(context.Background(), c, kube.NewForClients("test", clientset, nil, nil), sampling.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1: %+v", len(rows), rows)
+	}
+	r := rows[0]
+	if r.Cluster != "test" || r.Kind != "StatefulSet" || r.Workload != "db" || r.Container != "app" || r.Samples != 4 {
+		t.Errorf("row = %s %s/%s/%s samples=%d", r.Cluster, r.Kind, r.Workload, r.Container, r.Samples)
+	}
+	if got, want := [4]int64{r.CPUMin, r.CPUAvg, r.CPUP95, r.CPUMax}, [4]int64{125, 468, 1000, 1000}; got != want {
+		t.Errorf("cpu stats = %v, want %v", got, want)
+	}
+	if got, want := [4]int64{r.MemMin, r.MemAvg, r.MemP95, r.MemMax}, [4]int64{256, 640, 1024, 1024}; got != want {
+		t.Errorf("mem stats = %v, want %v", got, want)
+	}
+	// cpuRequest = p50(250) × 0.5；cpuLimit = p95(1000) × 1.0 × 1.2；memRequest = avg(640) × 0.8；memLimit = p100(1024) × 1.5
+	if got, want := [4]int64{r.CPURequest, r.CPULimit, r.MemRequest, r.MemLimit}, [4]int64{125, 1200, 512, 1536}; got != want {
+		t.Errorf("advice = %v, want %v", got, want)
+	}
+	if r.Policy != builtinPolicy || !strings.HasPrefix(r.Basis, "cpuRequest=p50 * 0.50(=125m); cpuLimit=p95 * 1.20(=1200m)") {
+		t.Errorf("policy = %s basis = %s", r.Policy, r.Basis)
+	}
+}
+
+func TestGuessOwner(t *testing.T) {
+	byName := map[string]string{"web": "Deployment/web", "db": "StatefulSet/db", "web-api": "Deployment/web-api"}
+	tests := []struct {
+		pod  string
+		want string
+	}{
+		{"db-3", "StatefulSet/db"},
+		{"web-7d9f8c-x2k4q", "Deployment/web"},
+		{"web-api-7d9f8c-x2k4q", "Deployment/web-api"},
+		{"web-api-0", "Deployment/web-api"},
+		{"cache-0", ""},
+		{"single", ""},
+	}
+	for _, tt := range tests {
+		got, ok := guessOwner(tt.pod, byName)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("guessOwner(%s) = %q, %v, want %q", tt.pod, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package paradise
+
+import (
+	"fmt"
+	"k8stools/pkg/config"
+	"k8stools/pkg/recommend"
+	"k8stools/pkg/sampling"
+)
+
+// builtinPolicy 未配置 recommend 策略时 Policy 列显示的名称，表示按 paradise.policy 计算
+const builtinPolicy = "paradise"
+
+// series 单个容器的使用量读数，cpu 单位为 m，mem 单位为 MiB
+type series struct {
+	cpu []int64
+	mem []int64
+}
+
+// usage 转换为 recommend 策略的输入
+func (s *series) usage() recommend.Usage {
+	return recommend.Usage{CPU: toFloat(s.cpu), Memory: toFloat(s.mem)}
+}
+
+func toFloat(values []int64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// advice 单个容器按策略计算出的建议值
+type advice struct {
+	cpuRequest, cpuLimit int64
+	memRequest, memLimit int64
+	text                 string
+	policy, basis        string
+}
+
+// fromRecommendation 使用 recommend 策略的建议值
+func fromRecommendation(r recommend.Recommendation) advice {
+	return advice{
+		cpuRequest: r.CPURequest,
+		cpuLimit:   r.CPULimit,
+		memRequest: r.MemRequest,
+		memLimit:   r.MemLimit,
+		text:       fmt.Sprintf("按 %s 策略计算", r.Policy),
+		policy:     r.Policy,
+		basis:      r.Basis,
+	}
+}
+
+// advise 按策略计算建议值，p 需已填充默认值（见 config.ApplyDefaults）
+// 阈值按 Requests 的基准判断；Limits 不低于 Requests
+func advise(p config.ParadisePolicy, s *series) advice {
+	a := advice{policy: builtinPolicy}
+
+	cpuRequestBase := basis(s.cpu, p.CPURequestPercentile)
+	cpuLimitBase := basis(s.cpu, p.CPULimitPercentile)
+	switch {
+	case cpuRequestBase < p.LowCPU:
+		a.cpuRequest = p.MinCPURequest
+		a.cpuLimit = p.MinCPULimit
+		a.text = "使用率较低，建议使用最小推荐值"
+	case cpuRequestBase > p.HighCPU:
+		a.cpuRequest = scale(cpuRequestBase, p.CPURequestFactor)
+		a.cpuLimit = scale(cpuLimitBase, p.CPULimitFactor*(1+p.CPUHeadroom))
+		a.text = "使用率较高，建议设置严格限制"
+	default:
+		a.cpuRequest = scale(cpuRequestBase, p.CPURequestFactor)
+		a.cpuLimit = scale(cpuLimitBase, p.CPULimitFactor*(1+p.CPUHeadroom))
+		a.text = "正常使用，建议标准配置"
+	}
+
+	memRequestBase := basis(s.mem, p.MemRequestPercentile)
+	memLimitBase := basis(s.mem, p.MemLimitPercentile)
+	switch {
+	case memRequestBase < p.LowMem:
+		a.memRequest = p.MinMemRequest
+		a.memLimit = p.MinMemLimit
+	case memRequestBase > p.HighMem:
+		a.memRequest = scale(memRequestBase, p.HighMemRequestFactor)
+		a.memLimit = scale(memLimitBase, p.MemLimitFactor*(1+p.MemHeadroom))
+	default:
+		a.memRequest = scale(memRequestBase, p.MemRequestFactor)
+		a.memLimit = scale(memLimitBase, p.MemLimitFactor*(1+p.MemHeadroom))
+	}
+
+	a.cpuLimit = max(a.cpuLimit, a.cpuRequest)
+	a.memLimit = max(a.memLimit, a.memRequest)
+	a.basis = fmt.Sprintf("cpuRequest=%s * %.2f(=%dm); cpuLimit=%s * %.2f(=%dm); memRequest=%s * %.2f(=%dMi); memLimit=%s * %.2f(=%dMi)",
+		basisName(p.CPURequestPercentile), p.CPURequestFactor, a.cpuRequest,
+		basisName(p.CPULimitPercentile), p.CPULimitFactor*(1+p.CPUHeadroom), a.cpuLimit,
+		basisName(p.MemRequestPercentile), memRequestFactor(p, memRequestBase), a.memRequest,
+		basisName(p.MemLimitPercentile), p.MemLimitFactor*(1+p.MemHeadroom), a.memLimit)
+	if cpuRequestBase < p.LowCPU || memRequestBase < p.LowMem {
+		a.basis += "; 低于 lowCpu/lowMem 时取最小推荐值"
+	}
+	return a
+}
+
+// basisName 返回基准在表达式中的写法，如 avg、p90
+func basisName(percentile float64) string {
+	if percentile == 0 {
+		return "avg"
+	}
+	return fmt.Sprintf("p%g", percentile)
+}
+
+func memRequestFactor(p config.ParadisePolicy, base int64) float64 {
+	if base > p.HighMem {
+		return p.HighMemRequestFactor
+	}
+	return p.MemRequestFactor
+}
+
+// basis 返回第 percentile 百分位数，percentile 为 0 时返回平均值
+func basis(values []int64, percentile float64) int64 {
+	if percentile == 0 {
+		return sampling.Summarize(values).Avg
+	}
+	return sampling.Percentile(values, percentile)
+}
+
+func scale(v int64, factor float64) int64 {
+	return int64(float64(v) * factor)
+}
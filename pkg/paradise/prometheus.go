@@ -0,0 +1,97 @@
+package paradise
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// 单个序列最多约 11000 个点，窗口较长时按比例放大查询步长
+const (
+	minStep       = 5 * time.Minute
+	maxDataPoints = 10000
+)
+
+// loadHistory 查询命名空间下各容器在窗口内的 CPU（m）与工作集内存（MiB）序列，键为 Pod 名、容器名
+func loadHistory(ctx context.Context, address, ns string, window time.Duration) (map[string]map[string]*series, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("创建 Prometheus 客户端失败: %w", err)
+	}
+	promAPI := v1.NewAPI(client)
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	end := time.Now()
+	r := v1.Range{Start: end.Add(-window), End: end, Step: max(minStep, window/maxDataPoints)}
+	filter := fmt.Sprintf(`namespace=%q,image!="",container!="",container!="POD"`, ns)
+	queries := []struct {
+		name  string
+		query string
+		add   func(s *series, v float64)
+	}{
+		{
+			name:  "CPU",
+			query: fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{%s}[5m])) by (pod, container)`, filter),
+			add:   func(s *series, v float64) { s.cpu = append(s.cpu, int64(v*1000)) },
+		},
+		{
+			name:  "内存",
+			query: fmt.Sprintf(`max(container_memory_working_set_bytes{%s}) by (pod, container)`, filter),
+			add:   func(s *series, v float64) { s.mem = append(s.mem, int64(v/(1024*1024))) },
+		},
+	}
+
+	history := make(map[string]map[string]*series)
+	for _, q := range queries {
+		result, warnings, err := promAPI.QueryRange(ctx, q.query, r)
+		if err != nil {
+			return nil, fmt.Errorf("查询 %s 失败: %w", q.name, err)
+		}
+		if len(warnings) > 0 {
+			fmt.Fprintf(os.Stderr, "%s 查询警告: %v\n", q.name, warnings)
+		}
+		matrix, ok := result.(model.Matrix)
+		if !ok {
+			continue
+		}
+		for _, stream := range matrix {
+			pod, container := string(stream.Metric["pod"]), string(stream.Metric["container"])
+			if history[pod] == nil {
+				history[pod] = make(map[string]*series)
+			}
+			s := history[pod][container]
+			if s == nil {
+				s = &series{}
+				history[pod][container] = s
+			}
+			for _, v := range stream.Values {
+				q.add(s, float64(v.Value))
+			}
+		}
+	}
+	return history, nil
+}
+
+// guessOwner 为已删除的 Pod 按命名规则推测工作负载：StatefulSet、DaemonSet、Job 等去掉最后一段，
+// Deployment 去掉最后两段（ReplicaSet 哈希与随机后缀）；byName 为工作负载名称到 Key 的映射
+func guessOwner(pod string, byName map[string]string) (string, bool) {
+	name := pod
+	for i := 0; i < 2; i++ {
+		dash := strings.LastIndex(name, "-")
+		if dash <= 0 {
+			return "", false
+		}
+		name = name[:dash]
+		if key, ok := byName[name]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
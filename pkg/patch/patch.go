@@ -0,0 +1,382 @@
+// Package patch 将容器资源建议（[]Change，由 paradise、trend 等命令转换而来）写成可直接应用的
+// strategic merge patch 或 kustomize patch，并生成调整前后容器资源的 unified diff
+package patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8stools/pkg/kube"
+	"k8stools/pkg/workload"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// 支持的 patch 格式
+const (
+	// FormatStrategic 只包含 spec 的 strategic merge patch，用于 kubectl patch --patch-file
+	FormatStrategic = "strategic"
+	// FormatKustomize 带 apiVersion/kind/metadata 的 patch，并在每个命名空间目录生成 kustomization.yaml
+	FormatKustomize = "kustomize"
+)
+
+// Formats 全部支持的 patch 格式
+var Formats = []string{FormatStrategic, FormatKustomize}
+
+// DiffFile 汇总全部容器资源变化的 unified diff 文件名
+const DiffFile = "resources.diff"
+
+// Change 单个容器的建议值，CPU 单位为 m，内存单位为 Mi，为 0 的字段保持原值
+type Change struct {
+	Namespace  string
+	Kind       string
+	Workload   string
+	Container  string
+	CPURequest int64
+	CPULimit   int64
+	MemRequest int64
+	MemLimit   int64
+}
+
+// Target 单个工作负载的全部容器变更
+type Target struct {
+	Namespace string
+	Kind      string
+	Name      string
+	// Containers 容器名 -> 调整后的资源
+	Containers map[string]corev1.ResourceRequirements
+	// Before 容器名 -> 调整前的资源，工作负载不存在或无法访问集群时为空
+	Before map[string]corev1.ResourceRequirements
+	// Init 属于 initContainers（如原生 sidecar）的容器
+	Init map[string]bool
+	// Replace 为 true 时以 $patch: replace 整体替换容器资源，用于回滚时删除调整时新增的字段
+	Replace bool
+	// Annotations 工作负载当前的注解，无法访问集群时为空
+	Annotations map[string]string
+}
+
+// Targets 按工作负载汇总变更；kc 不为 nil 时读取工作负载当前的 Pod 模板作为调整前的值，并记录工作负载的注解，
+// 集群中不存在的工作负载、Pod 模板不可修改的 Job 以及模板中没有的容器会被跳过并以错误返回
+func Targets(ctx context.Context, kc kube.Client, changes []Change) ([]Target, error) {
+	var errs []error
+	byKey := make(map[string]*Target)
+	var keys []string
+	for _, ch := range changes {
+		if ch.Kind == workload.KindJob {
+			errs = append(errs, fmt.Errorf("%s/Job/%s: Job 的 Pod 模板不可修改，已跳过", ch.Namespace, ch.Workload))
+			continue
+		}
+		if apiVersion(ch.Kind) == "" {
+			errs = append(errs, fmt.Errorf("%s/%s/%s: 不支持的工作负载类型，已跳过", ch.Namespace, ch.Kind, ch.Workload))
+			continue
+		}
+		key := ch.Namespace + "/" + ch.Kind + "/" + ch.Workload
+		t, ok := byKey[key]
+		if !ok {
+			t = &Target{
+				Namespace:  ch.Namespace,
+				Kind:       ch.Kind,
+				Name:       ch.Workload,
+				Containers: make(map[string]corev1.ResourceRequirements),
+				Before:     make(map[string]corev1.ResourceRequirements),
+				Init:       make(map[string]bool),
+			}
+			byKey[key] = t
+			keys = append(keys, key)
+		}
+		t.Containers[ch.Container] = merge(t.Containers[ch.Container], ch)
+	}
+	sort.Strings(keys)
+
+	workloads := make(map[string]*workload.Workload)
+	if kc != nil {
+		listed := make(map[string]bool)
+		for _, key := range keys {
+			ns := byKey[key].Namespace
+			if listed[ns] {
+				continue
+			}
+			listed[ns] = true
+			list, _, err := workload.List(ctx, kc.Kubernetes(), ns)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+			}
+			for i := range list {
+				w := &list[i]
+				workloads[ns+"/"+w.Key()] = w
+			}
+		}
+	}
+
+	var targets []Target
+	for _, key := range keys {
+		t := byKey[key]
+		if kc == nil {
+			targets = append(targets, *t)
+			continue
+		}
+		w, ok := workloads[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: 集群中不存在该工作负载，已跳过", key))
+			continue
+		}
+		t.Annotations = w.Annotations
+		tpl := &w.Template
+		current := make(map[string]corev1.ResourceRequirements)
+		for _, c := range tpl.Spec.InitContainers {
+			current[c.Name] = c.Resources
+			t.Init[c.Name] = true
+		}
+		for _, c := range tpl.Spec.Containers {
+			current[c.Name] = c.Resources
+		}
+		for name, after := range t.Containers {
+			before, ok := current[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: Pod 模板中没有容器 %s，已跳过", key, name))
+				delete(t.Containers, name)
+				continue
+			}
+			t.Before[name] = before
+			t.Containers[name] = apply(before, after)
+		}
+		if len(t.Containers) > 0 {
+			targets = append(targets, *t)
+		}
+	}
+	return targets, errors.Join(errs...)
+}
+
+// merge 将变更合并到已有的资源中，同一容器有多条变更时取较大值
+func merge(r corev1.ResourceRequirements, ch Change) corev1.ResourceRequirements {
+	set := func(list *corev1.ResourceList, name corev1.ResourceName, q resource.Quantity, v int64) {
+		if v <= 0 {
+			return
+		}
+		if *list == nil {
+			*list = corev1.ResourceList{}
+		}
+		if cur, ok := (*list)[name]; ok && cur.Cmp(q) >= 0 {
+			return
+		}
+		(*list)[name] = q
+	}
+	set(&r.Requests, corev1.ResourceCPU, CPU(ch.CPURequest), ch.CPURequest)
+	set(&r.Limits, corev1.ResourceCPU, CPU(ch.CPULimit), ch.CPULimit)
+	set(&r.Requests, corev1.ResourceMemory, Memory(ch.MemRequest), ch.MemRequest)
+	set(&r.Limits, corev1.ResourceMemory, Memory(ch.MemLimit), ch.MemLimit)
+	return r
+}
+
+// apply 以 after 中的值覆盖 before，保留 before 中的其他资源（如 ephemeral-storage）
+func apply(before, after corev1.ResourceRequirements) corev1.ResourceRequirements {
+	out := *before.DeepCopy()
+	for name, q := range after.Requests {
+		if out.Requests == nil {
+			out.Requests = corev1.ResourceList{}
+		}
+		out.Requests[name] = q
+	}
+	for name, q := range after.Limits {
+		if out.Limits == nil {
+			out.Limits = corev1.ResourceList{}
+		}
+		out.Limits[name] = q
+	}
+	return out
+}
+
+// CPU 将毫核转换为 Quantity
+func CPU(milli int64) resource.Quantity {
+	return *resource.NewMilliQuantity(milli, resource.DecimalSI)
+}
+
+// Memory 将 Mi 转换为 Quantity
+func Memory(mi int64) resource.Quantity {
+	return *resource.NewQuantity(mi*1024*1024, resource.BinarySI)
+}
+
+// apiVersion 返回工作负载类型对应的 apiVersion，不支持的类型返回空
+func apiVersion(kind string) string {
+	switch kind {
+	case workload.KindDeployment, workload.KindStatefulSet, workload.KindDaemonSet, workload.KindReplicaSet:
+		return "apps/v1"
+	case workload.KindJob, workload.KindCronJob:
+		return "batch/v1"
+	}
+	return ""
+}
+
+// Spec 返回只修改容器资源的 strategic merge patch 内容（spec 部分），CronJob 修改 jobTemplate 中的 Pod 模板
+func (t *Target) Spec() map[string]interface{} {
+	var containers, initContainers []interface{}
+	for _, name := range t.names() {
+		var resources interface{} = t.Containers[name]
+		if t.Replace {
+			resources = replaceResources(t.Containers[name])
+		}
+		c := map[string]interface{}{"name": name, "resources": resources}
+		if t.Init[name] {
+			initContainers = append(initContainers, c)
+		} else {
+			containers = append(containers, c)
+		}
+	}
+	podSpec := map[string]interface{}{}
+	if len(containers) > 0 {
+		podSpec["containers"] = containers
+	}
+	if len(initContainers) > 0 {
+		podSpec["initContainers"] = initContainers
+	}
+	template := map[string]interface{}{"template": map[string]interface{}{"spec": podSpec}}
+	if t.Kind == workload.KindCronJob {
+		return map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": template}}
+	}
+	return template
+}
+
+// replaceResources 返回带 $patch: replace 指令的资源，未出现的 requests/limits 会被删除
+func replaceResources(r corev1.ResourceRequirements) map[string]interface{} {
+	out := map[string]interface{}{"$patch": "replace"}
+	if len(r.Requests) > 0 {
+		out["requests"] = r.Requests
+	}
+	if len(r.Limits) > 0 {
+		out["limits"] = r.Limits
+	}
+	return out
+}
+
+func (t *Target) names() []string {
+	names := make([]string, 0, len(t.Containers))
+	for name := range t.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FileName 返回 patch 文件名，如 deployment-web.yaml
+func (t *Target) FileName() string {
+	return strings.ToLower(t.Kind) + "-" + t.Name + ".yaml"
+}
+
+// Render 按格式生成 patch 文件内容
+func (t *Target) Render(format string) ([]byte, error) {
+	var doc map[string]interface{}
+	var header string
+	switch format {
+	case FormatStrategic:
+		doc = map[string]interface{}{"spec": t.Spec()}
+		header = fmt.Sprintf("# kubectl patch %s %s -n %s --type strategic --patch-file %s\n",
+			strings.ToLower(t.Kind), t.Name, t.Namespace, t.FileName())
+	case FormatKustomize:
+		doc = map[string]interface{}{
+			"apiVersion": apiVersion(t.Kind),
+			"kind":       t.Kind,
+			"metadata":   map[string]interface{}{"name": t.Name, "namespace": t.Namespace},
+			"spec":       t.Spec(),
+		}
+	default:
+		return nil, fmt.Errorf("不支持的 patch 格式: %s (请使用 %s)", format, strings.Join(Formats, "/"))
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 %s/%s 的 patch 失败: %w", t.Kind, t.Name, err)
+	}
+	return append([]byte(header), data...), nil
+}
+
+// Diff 返回各容器调整前后资源的 unified diff，每行一个值，如 "requests.cpu: 200m"
+func (t *Target) Diff() string {
+	var b strings.Builder
+	for _, name := range t.names() {
+		label := fmt.Sprintf("%s/%s/%s/%s", t.Namespace, t.Kind, t.Name, name)
+		b.WriteString(unifiedDiff("a/"+label, "b/"+label, resourcesText(t.Before[name]), resourcesText(t.Containers[name])))
+	}
+	return b.String()
+}
+
+// resourcesText 按 limits、requests 及资源名排序逐行输出，便于逐项对比
+func resourcesText(r corev1.ResourceRequirements) string {
+	var b strings.Builder
+	for _, section := range []struct {
+		name string
+		list corev1.ResourceList
+	}{{"limits", r.Limits}, {"requests", r.Requests}} {
+		names := make([]string, 0, len(section.list))
+		for name := range section.list {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			q := section.list[corev1.ResourceName(name)]
+			fmt.Fprintf(&b, "%s.%s: %s\n", section.name, name, q.String())
+		}
+	}
+	return b.String()
+}
+
+// Write 将 targets 按命名空间写入 dir/<命名空间>/<kind>-<name>.yaml，kustomize 格式同时生成 kustomization.yaml，
+// 全部容器的资源变化写入 dir/resources.diff，返回写出的文件
+func Write(dir, format string, targets []Target) ([]string, error) {
+	var files []string
+	var diff strings.Builder
+	patches := make(map[string][]string)
+	var namespaces []string
+	for i := range targets {
+		t := &targets[i]
+		data, err := t.Render(format)
+		if err != nil {
+			return files, err
+		}
+		nsDir := filepath.Join(dir, t.Namespace)
+		if err := os.MkdirAll(nsDir, 0755); err != nil {
+			return files, fmt.Errorf("创建目录失败: %w", err)
+		}
+		target := filepath.Join(nsDir, t.FileName())
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return files, fmt.Errorf("写入 patch 失败: %w", err)
+		}
+		files = append(files, target)
+		if _, ok := patches[t.Namespace]; !ok {
+			namespaces = append(namespaces, t.Namespace)
+		}
+		patches[t.Namespace] = append(patches[t.Namespace], t.FileName())
+
+		diff.WriteString(t.Diff())
+	}
+
+	if format == FormatKustomize {
+		for _, ns := range namespaces {
+			var b strings.Builder
+			b.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n")
+			b.WriteString("# resources:\n#   - <原有清单>\npatches:\n")
+			for _, f := range patches[ns] {
+				fmt.Fprintf(&b, "  - path: %s\n", f)
+			}
+			target := filepath.Join(dir, ns, "kustomization.yaml")
+			if err := os.WriteFile(target, []byte(b.String()), 0644); err != nil {
+				return files, fmt.Errorf("写入 kustomization.yaml 失败: %w", err)
+			}
+			files = append(files, target)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return files, fmt.Errorf("创建目录失败: %w", err)
+	}
+	target := filepath.Join(dir, DiffFile)
+	if err := os.WriteFile(target, []byte(diff.String()), 0644); err != nil {
+		return files, fmt.Errorf("写入 diff 失败: %w", err)
+	}
+	return append(files, target), nil
+}
@@ -0,0 +1,247 @@
+package patch
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/kube"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 中的 golden 文件")
+
+func list(pairs ...string) corev1.ResourceList {
+	l := corev1.ResourceList{}
+	for i := 0; i < len(pairs); i += 2 {
+		l[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return l
+}
+
+// golden 与 testdata/<name>.golden 比较，-update 时重新生成
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s 与 golden 文件不一致:\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+func testClient() kube.Client {
+	always := corev1.ContainerRestartPolicyAlways
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Name:          "mesh",
+			RestartPolicy: &always,
+			Resources:     corev1.ResourceRequirements{Requests: list("cpu", "100m", "memory", "64Mi")},
+		}},
+		Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{
+				Requests: list("cpu", "200m", "memory", "256Mi", "ephemeral-storage", "1Gi"),
+				Limits:   list("cpu", "1", "memory", "512Mi"),
+			},
+		}},
+	}
+	return kube.NewForClients("test", fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: spec}},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "report"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{Spec: spec},
+			}}},
+		},
+	), nil, nil)
+}
+
+var testChanges = []Change{
+	{Namespace: "demo", Kind: "Deployment", Workload: "web", Container: "app", CPURequest: 300, MemRequest: 384, MemLimit: 768},
+	// 同一容器有多条变更时取较大值
+	{Namespace: "demo", Kind: "Deployment", Workload: "web", Container: "app", CPURequest: 250, CPULimit: 600},
+	{Namespace: "demo", Kind: "Deployment", Workload: "web", Container: "mesh", CPURequest: 50, CPULimit: 100},
+	{Namespace: "demo", Kind: "CronJob", Workload: "report", Container: "app", CPURequest: 500, MemRequest: 128},
+	// 以下均被跳过
+	{Namespace: "demo", Kind: "Deployment", Workload: "web", Container: "missing", CPURequest: 100},
+	{Namespace: "demo", Kind: "Deployment", Workload: "gone", Container: "app", CPURequest: 100},
+	{Namespace: "demo", Kind: "Job", Workload: "migrate", Container: "app", CPURequest: 100},
+	{Namespace: "demo", Kind: "Pod", Workload: "standalone", Container: "app", CPURequest: 100},
+}
+
+func TestTargets(t *testing.T) {
+	targets, err := Targets(context.Background(), testClient(), testChanges)
+	if err == nil {
+		t.Fatal("Targets 应返回被跳过的变更")
+	}
+	for _, want := range []string{"没有容器 missing", "demo/Deployment/gone: 集群中不存在", "Job 的 Pod 模板不可修改", "不支持的工作负载类型"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q 应包含 %q", err, want)
+		}
+	}
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(targets))
+	}
+
+	report, web := targets[0], targets[1]
+	if report.Kind != "CronJob" || web.Kind != "Deployment" {
+		t.Fatalf("targets = %s, %s, want CronJob, Deployment", report.Kind, web.Kind)
+	}
+	if !reflect.DeepEqual(web.Init, map[string]bool{"mesh": true}) {
+		t.Errorf("Init = %v, want mesh", web.Init)
+	}
+	// 未给出的值保持原值，ephemeral-storage 等其他资源保留
+	wantApp := corev1.ResourceRequirements{
+		Requests: list("cpu", "300m", "memory", "384Mi", "ephemeral-storage", "1Gi"),
+		Limits:   list("cpu", "600m", "memory", "768Mi"),
+	}
+	if got := web.Containers["app"]; !equalResources(got, wantApp) {
+		t.Errorf("app = %v, want %v", got, wantApp)
+	}
+	if got := web.Before["app"]; got.Requests.Cpu().String() != "200m" {
+		t.Errorf("Before[app] requests.cpu = %s, want 200m", got.Requests.Cpu())
+	}
+}
+
+func equalResources(a, b corev1.ResourceRequirements) bool {
+	eq := func(x, y corev1.ResourceList) bool {
+		if len(x) != len(y) {
+			return false
+		}
+		for name, q := range x {
+			if v, ok := y[name]; !ok || q.Cmp(v) != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return eq(a.Requests, b.Requests) && eq(a.Limits, b.Limits)
+}
+
+func TestRender(t *testing.T) {
+	targets, _ := Targets(context.Background(), testClient(), testChanges)
+	report, web := targets[0], targets[1]
+	replace := web
+	replace.Replace = true
+
+	tests := []struct {
+		name   string
+		target Target
+		format string
+	}{
+		{"deployment-strategic", web, FormatStrategic},
+		{"deployment-kustomize", web, FormatKustomize},
+		{"cronjob-strategic", report, FormatStrategic},
+		{"cronjob-kustomize", report, FormatKustomize},
+		{"deployment-replace", replace, FormatStrategic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.target.Render(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.name, got)
+		})
+	}
+	if _, err := web.Render("json"); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	targets, _ := Targets(context.Background(), testClient(), testChanges)
+	golden(t, "deployment.diff", []byte(targets[1].Diff()))
+}
+
+func TestWrite(t *testing.T) {
+	targets, _ := Targets(context.Background(), testClient(), testChanges)
+	dir := t.TempDir()
+	files, err := Write(dir, FormatKustomize, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, f := range files {
+		r, _ := filepath.Rel(dir, f)
+		rel = append(rel, r)
+	}
+	want := []string{"demo/cronjob-report.yaml", "demo/deployment-web.yaml", "demo/kustomization.yaml", DiffFile}
+	if !reflect.DeepEqual(rel, want) {
+		t.Errorf("Write() = %v, want %v", rel, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "demo", "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "kustomization.yaml", data)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "相同", a: "x\ny\n", b: "x\ny\n", want: ""},
+		{
+			name: "修改中间一行",
+			a:    "limits.cpu: 1\nrequests.cpu: 200m\nrequests.memory: 256Mi\n",
+			b:    "limits.cpu: 1\nrequests.cpu: 300m\nrequests.memory: 256Mi\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n limits.cpu: 1\n-requests.cpu: 200m\n+requests.cpu: 300m\n requests.memory: 256Mi\n",
+		},
+		{
+			name: "新增",
+			a:    "requests.cpu: 200m\n",
+			b:    "limits.cpu: 400m\nrequests.cpu: 200m\n",
+			want: "--- a\n+++ b\n@@ -1,1 +1,2 @@\n+limits.cpu: 400m\n requests.cpu: 200m\n",
+		},
+		{
+			name: "删除",
+			a:    "limits.cpu: 400m\nrequests.cpu: 200m\n",
+			b:    "requests.cpu: 200m\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,1 @@\n-limits.cpu: 400m\n requests.cpu: 200m\n",
+		},
+		{
+			name: "原内容为空",
+			a:    "",
+			b:    "requests.cpu: 200m\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+requests.cpu: 200m\n",
+		},
+		{
+			// 最长公共子序列保留 b、d，其余按先删后增输出
+			name: "多处修改",
+			a:    "a\nb\nc\nd\n",
+			b:    "b\nx\nd\ny\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-a\n b\n-c\n+x\n d\n+y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
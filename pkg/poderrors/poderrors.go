@@ -0,0 +1,76 @@
+// Package poderrors 检测处于 CrashLoopBackOff、ImagePullBackOff 或异常退出的容器
+package poderrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodError 异常容器记录
+type PodError struct {
+	Cluster   string `json:"cluster" header:"Cluster"`
+	Namespace string `json:"namespace" header:"Namespace"`
+	Pod       string `json:"pod" header:"Pod"`
+	Container string `json:"container" header:"Container"`
+	Reason    string `json:"reason" header:"状态原因"`
+	Message   string `json:"message" header:"错误信息"`
+}
+
+// GetPodError 返回单个集群内配置的命名空间下的异常容器
+// 个别命名空间失败时返回已收集的结果以及合并后的错误
+func GetPodError(ctx context.Context, c *config.Config, kc kube.Client) ([]PodError, error) {
+	return collectPodErrors(ctx, kc.Name(), c.NameSpace, kc.Kubernetes())
+}
+
+func collectPodErrors(ctx context.Context, cluster string, namespaces []string, clientset kubernetes.Interface) ([]PodError, error) {
+	var rows []PodError
+	var errs []error
+
+	for _, ns := range namespaces {
+		pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("获取命名空间 %s 的 Pod 失败: %w", ns, err))
+			continue
+		}
+
+		for _, pod := range pods.Items {
+			for _, cs := range pod.Status.ContainerStatuses {
+				// 检查是否为 Waiting 且有常见错误状态
+				if cs.State.Waiting != nil {
+					reason := cs.State.Waiting.Reason
+					if reason == "CrashLoopBackOff" || reason == "Error" || reason == "ImagePullBackOff" {
+						message := cs.State.Waiting.Message
+						rows = append(rows, PodError{
+							Cluster:   cluster,
+							Namespace: ns,
+							Pod:       pod.Name,
+							Container: cs.Name,
+							Reason:    reason,
+							Message:   message,
+						})
+					}
+				}
+				// 检查 Terminated 状态是否是失败
+				if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+					reason := cs.State.Terminated.Reason
+					message := cs.State.Terminated.Message
+					rows = append(rows, PodError{
+						Cluster:   cluster,
+						Namespace: ns,
+						Pod:       pod.Name,
+						Container: cs.Name,
+						Reason:    reason,
+						Message:   message,
+					})
+				}
+			}
+		}
+	}
+	return rows, errors.Join(errs...)
+}
@@ -0,0 +1,48 @@
+// Package prometheus 提供 Prometheus 地址的连通性探测
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Probe 请求 /api/v1/status/buildinfo 检查地址是否为可用的 Prometheus，返回其版本号
+func Probe(ctx context.Context, address string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	u := strings.TrimRight(address, "/") + "/api/v1/status/buildinfo"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("连接 Prometheus 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("Prometheus返回错误状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+
+	var res struct {
+		Status string `json:"status"`
+		Data   struct {
+			Version string `json:"version"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("解析 Prometheus 响应失败: %w", err)
+	}
+	if res.Status != "success" {
+		return "", fmt.Errorf("Prometheus查询失败: %s", res.Status)
+	}
+	return res.Data.Version, nil
+}
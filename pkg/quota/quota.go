@@ -0,0 +1,283 @@
+// Package quota 读取命名空间的 LimitRange 与 ResourceQuota：按 LimitRange 修正资源建议、
+// 检查建议生效后是否超出 ResourceQuota，并汇总 ResourceQuota 的使用情况
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"k8stools/pkg/workload"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const mi = 1024 * 1024
+
+// Constraints 单个命名空间的 LimitRange 与 ResourceQuota
+type Constraints struct {
+	Namespace   string
+	LimitRanges []corev1.LimitRange
+	Quotas      []corev1.ResourceQuota
+}
+
+// Load 读取命名空间下的 LimitRange 与 ResourceQuota，其中一类获取失败时另一类仍然可用
+func Load(ctx context.Context, clientset kubernetes.Interface, ns string) (*Constraints, error) {
+	c := &Constraints{Namespace: ns}
+	var errs []error
+	limitRanges, err := clientset.CoreV1().LimitRanges(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取命名空间 %s 的 LimitRange 失败: %w", ns, err))
+	} else {
+		c.LimitRanges = limitRanges.Items
+	}
+	quotas, err := clientset.CoreV1().ResourceQuotas(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取命名空间 %s 的 ResourceQuota 失败: %w", ns, err))
+	} else {
+		c.Quotas = quotas.Items
+	}
+	return c, errors.Join(errs...)
+}
+
+// Item 单个容器的建议值，CPU 单位为 m，内存单位为 Mi，0 表示没有给出该项建议
+type Item struct {
+	Kind       string
+	Workload   string
+	Container  string
+	CPURequest int64
+	CPULimit   int64
+	MemRequest int64
+	MemLimit   int64
+	// Reasons 修正或超限的说明
+	Reasons []string
+}
+
+// Reason 合并后的说明，没有问题时为空
+func (it *Item) Reason() string {
+	return strings.Join(it.Reasons, "; ")
+}
+
+// Enforce 按 LimitRange 修正 items 中的建议值，再检查全部建议生效后是否超出 ResourceQuota
+// workloads 提供当前 Pod 模板与副本数，用于估算配额变化；找不到工作负载的建议不参与配额估算
+func (c *Constraints) Enforce(items []Item, workloads []workload.Workload) {
+	if c == nil {
+		return
+	}
+	for i := range items {
+		c.clamp(&items[i])
+	}
+	c.checkQuota(items, workloads)
+}
+
+// clamp 按 Container 类型的 LimitRange 修正单个容器的建议值：先满足 min/max，再满足 maxLimitRequestRatio
+// Pod 类型的限制针对 Pod 内全部容器的合计，单个容器的建议无法判断，这里不检查
+func (c *Constraints) clamp(it *Item) {
+	for _, lr := range c.LimitRanges {
+		for _, l := range lr.Spec.Limits {
+			if l.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			where := "LimitRange " + lr.Name
+			bound(it, where, corev1.ResourceCPU, l, &it.CPURequest, &it.CPULimit)
+			bound(it, where, corev1.ResourceMemory, l, &it.MemRequest, &it.MemLimit)
+		}
+	}
+}
+
+func bound(it *Item, where string, name corev1.ResourceName, l corev1.LimitRangeItem, request, limit *int64) {
+	adjust := func(field string, v *int64, to int64, why string) {
+		it.Reasons = append(it.Reasons, fmt.Sprintf("%s %s %s，已由 %s 调整为 %s", where, field, why, format(name, *v), format(name, to)))
+		*v = to
+	}
+	values := []struct {
+		field string
+		v     *int64
+	}{{"requests." + string(name), request}, {"limits." + string(name), limit}}
+
+	if q, ok := l.Min[name]; ok {
+		lo := toUnit(name, q, true)
+		for _, f := range values {
+			if *f.v > 0 && *f.v < lo {
+				adjust(f.field, f.v, lo, "低于最小值 "+format(name, lo))
+			}
+		}
+	}
+	if q, ok := l.Max[name]; ok {
+		hi := toUnit(name, q, false)
+		for _, f := range values {
+			if *f.v > hi {
+				adjust(f.field, f.v, hi, "超过最大值 "+format(name, hi))
+			}
+		}
+	}
+	if *request > 0 && *limit > 0 && *limit < *request {
+		adjust(values[1].field, limit, *request, "小于 requests")
+	}
+	if q, ok := l.MaxLimitRequestRatio[name]; ok && *request > 0 && *limit > 0 {
+		ratio := q.AsApproximateFloat64()
+		if hi := int64(math.Floor(float64(*request)*ratio + 1e-9)); *limit > hi {
+			adjust(values[1].field, limit, hi, fmt.Sprintf("超过 maxLimitRequestRatio %g", ratio))
+		}
+	}
+}
+
+// quotaResources Enforce 检查的配额项，cpu/memory 与 requests.cpu/requests.memory 等价
+var quotaResources = []corev1.ResourceName{
+	corev1.ResourceRequestsCPU, corev1.ResourceCPU, corev1.ResourceLimitsCPU,
+	corev1.ResourceRequestsMemory, corev1.ResourceMemory, corev1.ResourceLimitsMemory,
+}
+
+// checkQuota 估算建议生效后各配额项的用量：已用量 + Σ(建议值 - 当前值) × 副本数，
+// 超出 hard 时为使该项增加的建议记录原因；配额按命名空间合计，无法确定该调整哪个容器，因此不修正建议值
+// 带 scopes/scopeSelector 的配额只统计部分 Pod，这里跳过
+func (c *Constraints) checkQuota(items []Item, workloads []workload.Workload) {
+	if len(c.Quotas) == 0 {
+		return
+	}
+	// 按实际找到的工作负载汇总（trend 中按 Pod 名称推测的建议 Kind 为空），同一容器有多条建议时取较大值
+	type key struct{ workload, container string }
+	type entry struct {
+		item     Item
+		current  corev1.ResourceRequirements
+		replicas int64
+	}
+	merged := make(map[key]*entry)
+	var order []key
+	keys := make([]key, len(items))
+	for i := range items {
+		it := &items[i]
+		w, current, ok := find(workloads, it.Kind, it.Workload, it.Container)
+		if !ok {
+			continue
+		}
+		k := key{w.Key(), it.Container}
+		keys[i] = k
+		e, ok := merged[k]
+		if !ok {
+			e = &entry{current: current, replicas: int64(w.Replicas)}
+			merged[k] = e
+			order = append(order, k)
+		}
+		e.item.CPURequest = max(e.item.CPURequest, it.CPURequest)
+		e.item.CPULimit = max(e.item.CPULimit, it.CPULimit)
+		e.item.MemRequest = max(e.item.MemRequest, it.MemRequest)
+		e.item.MemLimit = max(e.item.MemLimit, it.MemLimit)
+	}
+
+	// 每个容器对各配额项的变化量，CPU 单位为 m，内存单位为 Mi
+	deltas := make(map[key]map[corev1.ResourceName]int64)
+	total := make(map[corev1.ResourceName]int64)
+	for _, k := range order {
+		e := merged[k]
+		d := make(map[corev1.ResourceName]int64)
+		change := func(name corev1.ResourceName, to int64, list corev1.ResourceList, res corev1.ResourceName) {
+			if to <= 0 {
+				return
+			}
+			from := int64(0)
+			if q, ok := list[res]; ok {
+				from = toUnit(res, q, true)
+			}
+			d[name] = (to - from) * e.replicas
+		}
+		change(corev1.ResourceRequestsCPU, e.item.CPURequest, e.current.Requests, corev1.ResourceCPU)
+		change(corev1.ResourceLimitsCPU, e.item.CPULimit, e.current.Limits, corev1.ResourceCPU)
+		change(corev1.ResourceRequestsMemory, e.item.MemRequest, e.current.Requests, corev1.ResourceMemory)
+		change(corev1.ResourceLimitsMemory, e.item.MemLimit, e.current.Limits, corev1.ResourceMemory)
+		d[corev1.ResourceCPU] = d[corev1.ResourceRequestsCPU]
+		d[corev1.ResourceMemory] = d[corev1.ResourceRequestsMemory]
+		deltas[k] = d
+		for name, v := range d {
+			total[name] += v
+		}
+	}
+
+	for _, q := range c.Quotas {
+		if len(q.Spec.Scopes) > 0 || q.Spec.ScopeSelector != nil {
+			continue
+		}
+		for _, name := range quotaResources {
+			hardQ, ok := q.Status.Hard[name]
+			if !ok {
+				if hardQ, ok = q.Spec.Hard[name]; !ok {
+					continue
+				}
+			}
+			if total[name] <= 0 {
+				continue
+			}
+			used := int64(0)
+			if usedQ, ok := q.Status.Used[name]; ok {
+				used = toUnit(name, usedQ, true)
+			}
+			hard := toUnit(name, hardQ, false)
+			projected := used + total[name]
+			if projected <= hard {
+				continue
+			}
+			reason := fmt.Sprintf("ResourceQuota %s 的 %s 预计超出（%s / %s）", q.Name, name, format(name, projected), format(name, hard))
+			for i := range items {
+				if deltas[keys[i]][name] > 0 {
+					items[i].Reasons = append(items[i].Reasons, reason)
+				}
+			}
+		}
+	}
+}
+
+// find 按 Kind（为空时只按名称）与名称查找工作负载，返回容器的当前资源
+func find(workloads []workload.Workload, kind, name, container string) (workload.Workload, corev1.ResourceRequirements, bool) {
+	for _, w := range workloads {
+		if w.Name != name || (kind != "" && w.Kind != kind) {
+			continue
+		}
+		for _, cs := range [][]corev1.Container{w.Template.Spec.Containers, w.Template.Spec.InitContainers} {
+			for _, ct := range cs {
+				if ct.Name == container {
+					return w, ct.Resources, true
+				}
+			}
+		}
+	}
+	return workload.Workload{}, corev1.ResourceRequirements{}, false
+}
+
+// toUnit 将数量转换为 m（CPU）或 Mi（内存），内存按 up 向上或向下取整
+func toUnit(name corev1.ResourceName, q resource.Quantity, up bool) int64 {
+	if isCPU(name) {
+		return q.MilliValue()
+	}
+	v := q.Value()
+	if up {
+		return (v + mi - 1) / mi
+	}
+	return v / mi
+}
+
+func format(name corev1.ResourceName, v int64) string {
+	if isCPU(name) {
+		return fmt.Sprintf("%dm", v)
+	}
+	return fmt.Sprintf("%dMi", v)
+}
+
+func isCPU(name corev1.ResourceName) bool {
+	return strings.HasSuffix(string(name), "cpu")
+}
+
+// sortedNames 按名称排序的资源名，保证输出稳定
+func sortedNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,214 @@
+package quota
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/workload"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func list(pairs ...string) corev1.ResourceList {
+	l := corev1.ResourceList{}
+	for i := 0; i < len(pairs); i += 2 {
+		l[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return l
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   corev1.LimitRangeItem
+		item    Item
+		want    [4]int64
+		reasons []string
+	}{
+		{
+			// 0 表示没有给出建议，不按 min 调高
+			name:    "低于最小值",
+			limit:   corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Min: list("cpu", "100m", "memory", "64Mi")},
+			item:    Item{CPURequest: 50, CPULimit: 80, MemLimit: 128},
+			want:    [4]int64{100, 100, 0, 128},
+			reasons: []string{"LimitRange lr requests.cpu 低于最小值 100m，已由 50m 调整为 100m", "LimitRange lr limits.cpu 低于最小值 100m，已由 80m 调整为 100m"},
+		},
+		{
+			name:    "超过最大值",
+			limit:   corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Max: list("cpu", "2", "memory", "1Gi")},
+			item:    Item{CPURequest: 500, CPULimit: 3000, MemRequest: 512, MemLimit: 2048},
+			want:    [4]int64{500, 2000, 512, 1024},
+			reasons: []string{"LimitRange lr limits.cpu 超过最大值 2000m，已由 3000m 调整为 2000m", "LimitRange lr limits.memory 超过最大值 1024Mi，已由 2048Mi 调整为 1024Mi"},
+		},
+		{
+			// 十进制单位换算为 Mi 时，min 向上取整、max 向下取整
+			name:    "内存十进制单位",
+			limit:   corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Min: list("memory", "100M"), Max: list("memory", "1G")},
+			item:    Item{MemRequest: 90, MemLimit: 1000},
+			want:    [4]int64{0, 0, 96, 953},
+			reasons: []string{"requests.memory 低于最小值 96Mi", "limits.memory 超过最大值 953Mi"},
+		},
+		{
+			name:    "limit 小于 request",
+			limit:   corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Max: list("cpu", "4")},
+			item:    Item{CPURequest: 500, CPULimit: 300, MemRequest: 256, MemLimit: 128},
+			want:    [4]int64{500, 500, 256, 256},
+			reasons: []string{"LimitRange lr limits.cpu 小于 requests，已由 300m 调整为 500m", "LimitRange lr limits.memory 小于 requests，已由 128Mi 调整为 256Mi"},
+		},
+		{
+			// 333m × 1.5 = 499.5m 向下取整，恰好整除时不调整
+			name:    "maxLimitRequestRatio 向下取整",
+			limit:   corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, MaxLimitRequestRatio: list("cpu", "1.5", "memory", "2")},
+			item:    Item{CPURequest: 333, CPULimit: 1000, MemRequest: 100, MemLimit: 200},
+			want:    [4]int64{333, 499, 100, 200},
+			reasons: []string{"LimitRange lr limits.cpu 超过 maxLimitRequestRatio 1.5，已由 1000m 调整为 499m"},
+		},
+		{
+			name:  "maxLimitRequestRatio 缺少 request 或 limit",
+			limit: corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, MaxLimitRequestRatio: list("cpu", "2")},
+			item:  Item{CPULimit: 1000, MemRequest: 100},
+			want:  [4]int64{0, 1000, 100, 0},
+		},
+		{
+			name:  "Pod 类型不检查",
+			limit: corev1.LimitRangeItem{Type: corev1.LimitTypePod, Min: list("cpu", "1"), Max: list("memory", "64Mi")},
+			item:  Item{CPURequest: 100, MemLimit: 512},
+			want:  [4]int64{100, 0, 0, 512},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Constraints{LimitRanges: []corev1.LimitRange{{
+				ObjectMeta: metav1.ObjectMeta{Name: "lr"},
+				Spec:       corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{tt.limit}},
+			}}}
+			it := tt.item
+			c.clamp(&it)
+			if got := [4]int64{it.CPURequest, it.CPULimit, it.MemRequest, it.MemLimit}; got != tt.want {
+				t.Errorf("clamp() = %v, want %v", got, tt.want)
+			}
+			if len(it.Reasons) != len(tt.reasons) {
+				t.Fatalf("reasons = %q, want %d 条", it.Reasons, len(tt.reasons))
+			}
+			for i, want := range tt.reasons {
+				if !strings.Contains(it.Reasons[i], want) {
+					t.Errorf("reasons[%d] = %q, want containing %q", i, it.Reasons[i], want)
+				}
+			}
+		})
+	}
+}
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func container(cpuReq, memReq, cpuLim, memLim string) corev1.PodSpec {
+	return corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: list("cpu", cpuReq, "memory", memReq),
+			Limits:   list("cpu", cpuLim, "memory", memLim),
+		},
+	}}}
+}
+
+// TestEnforce 从 fake clientset 读取 LimitRange、ResourceQuota 与工作负载，检查修正后的建议值与配额预估
+func TestEnforce(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web"},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(3), Template: corev1.PodTemplateSpec{
+				Spec: container("200m", "256Mi", "1", "512Mi"),
+			}},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "db"},
+			Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(1), Template: corev1.PodTemplateSpec{
+				Spec: container("200m", "1Gi", "1", "1Gi"),
+			}},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "limits"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+				{Type: corev1.LimitTypeContainer, Max: list("cpu", "600m")},
+			}},
+		},
+		// status 中的 hard/used 优先
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "compute"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: list("requests.cpu", "10", "limits.memory", "4Gi")},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: list("requests.cpu", "2", "limits.memory", "4Gi"),
+				Used: list("requests.cpu", "1", "limits.memory", "2Gi"),
+			},
+		},
+		// 没有 status 时取 spec.hard，used 按 0 计
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mem"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: list("requests.memory", "1Gi")},
+		},
+		// 带 scopes 的配额只统计部分 Pod，跳过
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "scoped"},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard:   list("requests.cpu", "100m"),
+				Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotBestEffort},
+			},
+		},
+	)
+	cons, err := Load(context.Background(), clientset, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workloads, _, err := workload.List(context.Background(), clientset, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []Item{
+		// 800m 先按 LimitRange 修正为 600m：requests.cpu 预计 1000m + (600m - 200m) × 3 + (100m - 200m) × 1 = 2100m
+		{Kind: "Deployment", Workload: "web", Container: "app", CPURequest: 800, MemRequest: 640, MemLimit: 1024},
+		// 同一容器的多条建议取较大值，同样记录超出原因
+		{Kind: "Deployment", Workload: "web", Container: "app", CPURequest: 500},
+		// Kind 为空时按名称查找；调低的建议抵消部分增量，但不记录原因
+		{Workload: "db", Container: "app", CPURequest: 100},
+		// 找不到工作负载的建议不参与估算
+		{Kind: "Deployment", Workload: "gone", Container: "app", CPURequest: 300},
+	}
+	cons.Enforce(items, workloads)
+
+	if items[0].CPURequest != 600 {
+		t.Errorf("web requests.cpu = %d, want 600", items[0].CPURequest)
+	}
+	cpuReason := "ResourceQuota compute 的 requests.cpu 预计超出（2100m / 2000m）"
+	memReason := "ResourceQuota mem 的 requests.memory 预计超出（1152Mi / 1024Mi）"
+	want := [][]string{
+		{cpuReason, memReason, "LimitRange limits requests.cpu 超过最大值 600m，已由 800m 调整为 600m"},
+		{cpuReason, memReason},
+		nil,
+		nil,
+	}
+	for i := range items {
+		got := append([]string(nil), items[i].Reasons...)
+		sort.Strings(got)
+		sort.Strings(want[i])
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("items[%d].Reasons = %q, want %q", i, got, want[i])
+		}
+	}
+
+	// 建议没有增量时不记录原因，Constraints 为 nil 时不做检查
+	none := []Item{{Kind: "Deployment", Workload: "web", Container: "app", CPURequest: 100, MemLimit: 512}}
+	cons.Enforce(none, workloads)
+	if len(none[0].Reasons) != 0 {
+		t.Errorf("Reasons = %q, want none", none[0].Reasons)
+	}
+	var nilCons *Constraints
+	nilCons.Enforce(none, workloads)
+}
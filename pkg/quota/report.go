@@ -0,0 +1,115 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// 配额使用状态
+const (
+	StatusOK       = "✅ 正常"
+	StatusWarning  = "⚠️ 接近上限"
+	StatusExceeded = "❌ 已用满"
+	StatusNone     = "⚠️ 未配置 ResourceQuota"
+)
+
+// WarnPercent 使用率达到该值时标记为接近上限
+const WarnPercent = 90
+
+// Usage 单个 ResourceQuota 配额项的使用情况；命名空间没有 ResourceQuota 时输出一行 Quota 为空的记录
+type Usage struct {
+	Cluster     string  `json:"cluster" header:"Cluster"`
+	Namespace   string  `json:"namespace" header:"Namespace"`
+	Quota       string  `json:"quota" header:"Quota"`
+	Scopes      string  `json:"scopes,omitempty" header:"Scopes"`
+	Resource    string  `json:"resource" header:"Resource"`
+	Used        string  `json:"used" header:"Used"`
+	Hard        string  `json:"hard" header:"Hard"`
+	UsedPct     float64 `json:"usedPct" header:"Used (%)" fmt:"%.1f"`
+	Status      string  `json:"status" header:"状态"`
+	LimitRanges int     `json:"limitRanges" header:"LimitRanges"`
+}
+
+// GetQuota 汇总单个集群内配置的命名空间下每个 ResourceQuota 各配额项的 used/hard，
+// 个别命名空间失败时返回已汇总的结果以及合并后的错误
+func GetQuota(ctx context.Context, c *config.Config, kc kube.Client) ([]Usage, error) {
+	var rows []Usage
+	var errs []error
+	for _, ns := range c.NameSpace {
+		cons, err := Load(ctx, kc.Kubernetes(), ns)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(cons.Quotas) == 0 {
+			if err == nil {
+				rows = append(rows, Usage{Cluster: kc.Name(), Namespace: ns, Status: StatusNone, LimitRanges: len(cons.LimitRanges)})
+			}
+			continue
+		}
+		for _, q := range cons.Quotas {
+			hard := q.Status.Hard
+			if len(hard) == 0 {
+				hard = q.Spec.Hard
+			}
+			for _, name := range sortedNames(hard) {
+				h := hard[name]
+				u := q.Status.Used[name]
+				rows = append(rows, Usage{
+					Cluster:     kc.Name(),
+					Namespace:   ns,
+					Quota:       q.Name,
+					Scopes:      scopes(q.Spec),
+					Resource:    string(name),
+					Used:        u.String(),
+					Hard:        h.String(),
+					UsedPct:     percent(u.AsApproximateFloat64(), h.AsApproximateFloat64()),
+					Status:      status(u.AsApproximateFloat64(), h.AsApproximateFloat64()),
+					LimitRanges: len(cons.LimitRanges),
+				})
+			}
+		}
+	}
+	return rows, errors.Join(errs...)
+}
+
+func percent(used, hard float64) float64 {
+	if hard == 0 {
+		if used > 0 {
+			return 100
+		}
+		return 0
+	}
+	return used / hard * 100
+}
+
+func status(used, hard float64) string {
+	pct := percent(used, hard)
+	switch {
+	case pct >= 100 && hard > 0 || hard == 0 && used > 0:
+		return StatusExceeded
+	case pct >= WarnPercent:
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// scopes 配额的作用范围，ScopeSelector 按 "scope operator values" 列出
+func scopes(spec corev1.ResourceQuotaSpec) string {
+	var parts []string
+	for _, s := range spec.Scopes {
+		parts = append(parts, string(s))
+	}
+	if spec.ScopeSelector != nil {
+		for _, e := range spec.ScopeSelector.MatchExpressions {
+			parts = append(parts, strings.TrimSpace(fmt.Sprintf("%s %s %s", e.ScopeName, e.Operator, strings.Join(e.Values, ","))))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,268 @@
+// Package recommend 为 paradise、trend、resourceAdvisor 提供统一的资源建议策略：
+// 内置 conservative、balanced、aggressive 三档，以及配置中以表达式定义的自定义策略，
+// 可按命名空间、工作负载选择，同一份使用量在不同命令中得到相同、可解释的建议
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/expr"
+	"k8stools/pkg/sampling"
+)
+
+// builtins 内置策略：conservative 以高分位留足余量，aggressive 贴近中位数以节省资源
+var builtins = map[string]config.RecommendPolicy{
+	config.PolicyConservative: {
+		Name:       config.PolicyConservative,
+		CPURequest: "p95 * 1.2",
+		CPULimit:   "max * 2",
+		MemRequest: "p99 * 1.2",
+		MemLimit:   "max * 1.5",
+	},
+	config.PolicyBalanced: {
+		Name:       config.PolicyBalanced,
+		CPURequest: "p90 * 1.1",
+		CPULimit:   "p99 * 1.5",
+		MemRequest: "p95 * 1.1",
+		MemLimit:   "max * 1.3",
+	},
+	config.PolicyAggressive: {
+		Name:       config.PolicyAggressive,
+		CPURequest: "p50",
+		CPULimit:   "p95 * 1.2",
+		MemRequest: "p90",
+		MemLimit:   "p99 * 1.2",
+	},
+}
+
+// Usage 单个容器的使用量读数，CPU 单位为 m，Memory 单位为 Mi
+type Usage struct {
+	CPU    []float64
+	Memory []float64
+}
+
+// Recommendation 策略给出的建议值，Basis 为计算依据，如 "cpuRequest=p90 * 1.1(=220m); ..."
+type Recommendation struct {
+	CPURequest int64
+	CPULimit   int64
+	MemRequest int64
+	MemLimit   int64
+	Policy     string
+	Basis      string
+}
+
+// Recommender 根据使用量给出建议值的策略
+type Recommender interface {
+	Name() string
+	Recommend(u Usage) Recommendation
+}
+
+// exprPolicy 以四个表达式定义的策略，内置策略与自定义策略均以此实现
+type exprPolicy struct {
+	name                 string
+	cpuRequest, cpuLimit *expr.Expr
+	memRequest, memLimit *expr.Expr
+	// minCPU、minMemory 建议值的下限，单位分别为 m、Mi
+	minCPU, minMemory int64
+}
+
+// New 编译策略表达式，建议值下限使用 config.DefaultRecommendMinCPU、config.DefaultRecommendMinMemory
+func New(p config.RecommendPolicy) (Recommender, error) {
+	r, err := compile(p, config.DefaultRecommendMinCPU, config.DefaultRecommendMinMemory)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func compile(p config.RecommendPolicy, minCPU, minMemory int64) (*exprPolicy, error) {
+	compiled := make([]*expr.Expr, 4)
+	for i, src := range []string{p.CPURequest, p.CPULimit, p.MemRequest, p.MemLimit} {
+		e, err := expr.Compile(src, config.PolicyVariables)
+		if err != nil {
+			return nil, fmt.Errorf("策略 %s: %w", p.Name, err)
+		}
+		compiled[i] = e
+	}
+	return &exprPolicy{
+		name:       p.Name,
+		cpuRequest: compiled[0],
+		cpuLimit:   compiled[1],
+		memRequest: compiled[2],
+		memLimit:   compiled[3],
+		minCPU:     minCPU,
+		minMemory:  minMemory,
+	}, nil
+}
+
+func (p *exprPolicy) Name() string {
+	return p.name
+}
+
+// Recommend 计算建议值，结果向上取整且不低于下限；Limits 不低于 Requests
+func (p *exprPolicy) Recommend(u Usage) Recommendation {
+	cpuVars, memVars := Variables(u.CPU), Variables(u.Memory)
+	var floored []string
+	eval := func(field string, e *expr.Expr, vars map[string]float64, floor int64, unit string) int64 {
+		v, ok := evalFloor(e, vars, floor)
+		if !ok {
+			floored = append(floored, fmt.Sprintf("%s 低于下限 %d%s，已取下限", field, floor, unit))
+		}
+		return v
+	}
+	r := Recommendation{
+		CPURequest: eval("cpuRequest", p.cpuRequest, cpuVars, p.minCPU, "m"),
+		CPULimit:   eval("cpuLimit", p.cpuLimit, cpuVars, p.minCPU, "m"),
+		MemRequest: eval("memRequest", p.memRequest, memVars, p.minMemory, "Mi"),
+		MemLimit:   eval("memLimit", p.memLimit, memVars, p.minMemory, "Mi"),
+		Policy:     p.name,
+	}
+	r.CPULimit = max(r.CPULimit, r.CPURequest)
+	r.MemLimit = max(r.MemLimit, r.MemRequest)
+	r.Basis = fmt.Sprintf("cpuRequest=%s(=%dm); cpuLimit=%s(=%dm); memRequest=%s(=%dMi); memLimit=%s(=%dMi)",
+		p.cpuRequest, r.CPURequest, p.cpuLimit, r.CPULimit, p.memRequest, r.MemRequest, p.memLimit, r.MemLimit)
+	if len(floored) > 0 {
+		r.Basis += "; " + strings.Join(floored, "; ")
+	}
+	return r
+}
+
+// evalFloor 计算表达式并向上取整，结果低于 floor 或无法计算（NaN/Inf）时返回 floor 与 false
+func evalFloor(e *expr.Expr, vars map[string]float64, floor int64) (int64, bool) {
+	v := e.Eval(vars)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return floor, false
+	}
+	// 减去极小值，避免 250 * 1.1 这类浮点误差被向上取整为 276
+	if n := int64(math.Ceil(v - 1e-9)); n >= floor {
+		return n, true
+	}
+	return floor, false
+}
+
+// Variables 计算表达式变量 min、avg、p50、p90、p95、p99、max，分位数与 sampling.Percentile 一致取最近秩（nearest-rank）；
+// values 为空时均为 0
+func Variables(values []float64) map[string]float64 {
+	vars := make(map[string]float64, len(config.PolicyVariables))
+	if len(values) == 0 {
+		for _, name := range config.PolicyVariables {
+			vars[name] = 0
+		}
+		return vars
+	}
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	vars["min"] = sampling.Percentile(values, 0)
+	vars["avg"] = total / float64(len(values))
+	vars["p50"] = sampling.Percentile(values, 50)
+	vars["p90"] = sampling.Percentile(values, 90)
+	vars["p95"] = sampling.Percentile(values, 95)
+	vars["p99"] = sampling.Percentile(values, 99)
+	vars["max"] = sampling.Percentile(values, 100)
+	return vars
+}
+
+// rule 编译后的选择规则
+type rule struct {
+	namespace string
+	workload  string
+	policy    Recommender
+}
+
+// Selector 按命名空间、工作负载选择策略
+type Selector struct {
+	rules    []rule
+	fallback Recommender
+}
+
+// NewSelector 按配置编译全部策略与规则，建议值下限为 rc.MinCPU、rc.MinMemory（为 0 时使用默认值）；
+// rc 为零值时 Lookup 总是返回 false
+func NewSelector(rc config.RecommendConfig) (*Selector, error) {
+	minCPU, minMemory := rc.MinCPU, rc.MinMemory
+	if minCPU == 0 {
+		minCPU = config.DefaultRecommendMinCPU
+	}
+	if minMemory == 0 {
+		minMemory = config.DefaultRecommendMinMemory
+	}
+	policies := make(map[string]Recommender, len(builtins)+len(rc.Policies))
+	for name, p := range builtins {
+		r, err := compile(p, minCPU, minMemory)
+		if err != nil {
+			return nil, err
+		}
+		policies[name] = r
+	}
+	for _, p := range rc.Policies {
+		if _, ok := policies[p.Name]; ok {
+			return nil, fmt.Errorf("策略名称 %q 重复或与内置策略重名", p.Name)
+		}
+		r, err := compile(p, minCPU, minMemory)
+		if err != nil {
+			return nil, err
+		}
+		policies[p.Name] = r
+	}
+	lookup := func(name string) (Recommender, error) {
+		r, ok := policies[name]
+		if !ok {
+			return nil, fmt.Errorf("未知策略 %q（可选 %s）", name, strings.Join(names(policies), "、"))
+		}
+		return r, nil
+	}
+
+	s := &Selector{}
+	if rc.Policy != "" {
+		r, err := lookup(rc.Policy)
+		if err != nil {
+			return nil, err
+		}
+		s.fallback = r
+	}
+	for _, ru := range rc.Rules {
+		r, err := lookup(ru.Policy)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, rule{namespace: ru.Namespace, workload: ru.Workload, policy: r})
+	}
+	return s, nil
+}
+
+// Lookup 返回第一条匹配的规则对应的策略，均未匹配时返回默认策略；
+// 未配置任何策略时返回 false，调用方沿用自身的内置算法
+func (s *Selector) Lookup(namespace, workload string) (Recommender, bool) {
+	if s == nil {
+		return nil, false
+	}
+	for _, r := range s.rules {
+		if match(r.namespace, namespace) && match(r.workload, workload) {
+			return r.policy, true
+		}
+	}
+	return s.fallback, s.fallback != nil
+}
+
+func match(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+func names(policies map[string]Recommender) []string {
+	out := make([]string, 0, len(policies))
+	for name := range policies {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,96 @@
+package recommend
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/config"
+)
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   map[string]float64
+	}{
+		{
+			name: "空读数",
+			want: map[string]float64{"min": 0, "avg": 0, "p50": 0, "p90": 0, "p95": 0, "p99": 0, "max": 0},
+		},
+		{
+			name:   "单个读数",
+			values: []float64{42.5},
+			want:   map[string]float64{"min": 42.5, "avg": 42.5, "p50": 42.5, "p90": 42.5, "p95": 42.5, "p99": 42.5, "max": 42.5},
+		},
+		{
+			// 最近秩：p50 取第 ceil(0.5*10)=5 个，p90 取第 9 个，p95/p99 取第 10 个
+			name:   "乱序读数",
+			values: []float64{100, 10, 90, 20, 80, 30, 70, 40, 60, 50},
+			want:   map[string]float64{"min": 10, "avg": 55, "p50": 50, "p90": 90, "p95": 100, "p99": 100, "max": 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Variables(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Variables(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendFloor(t *testing.T) {
+	policy := config.RecommendPolicy{Name: "test", CPURequest: "p50", CPULimit: "max * 2", MemRequest: "avg", MemLimit: "avg / 0 + max"}
+	tests := []struct {
+		name      string
+		rc        config.RecommendConfig
+		usage     Usage
+		want      [4]int64
+		basisHint []string
+	}{
+		{
+			name:  "高于下限",
+			usage: Usage{CPU: []float64{100, 200}, Memory: []float64{256, 512}},
+			want:  [4]int64{100, 400, 384, 512},
+		},
+		{
+			name:      "默认下限 10m/32Mi",
+			usage:     Usage{CPU: []float64{0, 1}, Memory: []float64{4, 8}},
+			want:      [4]int64{10, 10, 32, 32},
+			basisHint: []string{"cpuRequest 低于下限 10m", "cpuLimit 低于下限 10m", "memRequest 低于下限 32Mi", "memLimit 低于下限 32Mi"},
+		},
+		{
+			name:      "配置的下限",
+			rc:        config.RecommendConfig{MinCPU: 50, MinMemory: 64},
+			usage:     Usage{CPU: []float64{20, 30}, Memory: []float64{100, 120}},
+			want:      [4]int64{50, 60, 110, 120},
+			basisHint: []string{"cpuRequest 低于下限 50m"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rc.Policies = []config.RecommendPolicy{policy}
+			tt.rc.Policy = policy.Name
+			s, err := NewSelector(tt.rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, ok := s.Lookup("demo", "web")
+			if !ok {
+				t.Fatal("Lookup 应返回默认策略")
+			}
+			rec := r.Recommend(tt.usage)
+			if got := [4]int64{rec.CPURequest, rec.CPULimit, rec.MemRequest, rec.MemLimit}; got != tt.want {
+				t.Errorf("Recommend() = %v, want %v", got, tt.want)
+			}
+			for _, hint := range tt.basisHint {
+				if !strings.Contains(rec.Basis, hint) {
+					t.Errorf("Basis %q 应包含 %q", rec.Basis, hint)
+				}
+			}
+			if len(tt.basisHint) == 0 && strings.Contains(rec.Basis, "下限") {
+				t.Errorf("Basis %q 不应提及下限", rec.Basis)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
+// Package resourceAdvisor 基于 Traefik 流量指标估算服务的资源与副本数
 package resourceAdvisor
 
 import (
-	"encoding/csv"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"k8stools/pkg/config"
+	"k8stools/pkg/recommend"
 )
 
 /*
@@ -37,6 +40,9 @@ const (
 	memLimitBase   = 1024 // Mi
 
 	replicaMin = 3
+
+	// builtinPolicy 未配置 recommend 策略时 Policy 列显示的名称，表示按 RPS 与系数计算
+	builtinPolicy = "resourceAdvisor"
 )
 
 /*
@@ -46,22 +52,25 @@ const (
 */
 
 type AdviceRecord struct {
-	Namespace           string
-	Service             string
-	WeightedRPS         float64
-	P95LatencyMs        float64
-	CPURequest          int
-	CPULimit            int
-	MemRequest          int
-	MemLimit            int
-	MinReplicas         int
-	RecommendedReplicas int
-	Decision            string
-	Risk                string
-	Confidence          string
-	Reason              string
-	MetricsWindow       string
-	GeneratedAt         string
+	Cluster             string  `json:"cluster" header:"Cluster"`
+	Namespace           string  `json:"namespace" header:"命名空间"`
+	Service             string  `json:"service" header:"服务"`
+	WeightedRPS         float64 `json:"weightedRps" header:"RPS（加权）" fmt:"%.2f"`
+	P95LatencyMs        float64 `json:"p95LatencyMs" header:"P95延迟（ms）" fmt:"%.1f"`
+	CPURequest          int     `json:"cpuRequest" header:"请求CPU（m）"`
+	CPULimit            int     `json:"cpuLimit" header:"限制CPU（m）"`
+	MemRequest          int     `json:"memRequest" header:"请求内存（Mi）"`
+	MemLimit            int     `json:"memLimit" header:"限制内存（Mi）"`
+	MinReplicas         int     `json:"minReplicas" header:"最小副本数"`
+	RecommendedReplicas int     `json:"recommendedReplicas" header:"推荐副本数"`
+	Decision            string  `json:"decision" header:"决策"`
+	Risk                string  `json:"risk" header:"风险等级"`
+	Confidence          string  `json:"confidence" header:"置信度"`
+	Reason              string  `json:"reason" header:"原因"`
+	Policy              string  `json:"policy" header:"策略"`
+	Basis               string  `json:"basis" header:"计算依据"`
+	MetricsWindow       string  `json:"metricsWindow" header:"指标窗口"`
+	GeneratedAt         string  `json:"generatedAt" header:"生成时间"`
 }
 
 /*
@@ -70,91 +79,41 @@ type AdviceRecord struct {
 =====================
 */
 
-func ResourceAdvisor(c *config.Config) error {
+// ResourceAdvisor 为配置的命名空间下通过 Traefik 暴露的服务生成资源与副本建议
+// cluster 为 Cluster 列显示的集群名称；个别命名空间失败时返回已生成的建议以及合并后的错误
+func ResourceAdvisor(ctx context.Context, c *config.Config, cluster string) ([]AdviceRecord, error) {
 	// 验证配置
 	if err := validateResourceAdvisorConfig(c); err != nil {
-		return fmt.Errorf("配置验证失败: %w", err)
+		return nil, fmt.Errorf("配置验证失败: %w", err)
 	}
-
-	// 创建带时间戳的输出文件
-	timestamp := time.Now().Format("2006-01-02_150405")
-	filename := fmt.Sprintf("resource_advice_%s.csv", timestamp)
-	file, err := os.Create(filename)
+	selector, err := recommend.NewSelector(c.Recommend)
 	if err != nil {
-		return fmt.Errorf("创建输出文件失败: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// 中文表头
-	writer.Write([]string{
-		"命名空间",
-		"服务",
-		"RPS（加权）",
-		"P95延迟（ms）",
-		"请求CPU（m）",
-		"限制CPU（m）",
-		"请求内存（Mi）",
-		"限制内存（Mi）",
-		"最小副本数",
-		"推荐副本数",
-		"决策",
-		"风险等级",
-		"置信度",
-		"原因",
-		"指标窗口",
-		"生成时间",
-	})
-
-	totalRecords := 0
-	successfulRecords := 0
+		return nil, fmt.Errorf("recommend 策略配置错误: %w", err)
+	}
+	// 系数为 0 时按默认值计算，填充后计算依据中显示的即为实际生效的系数
+	cfg := *c
+	config.ApplyDefaults(&cfg)
+	c = &cfg
 
+	var rows []AdviceRecord
+	var errs []error
 	for _, ns := range c.NameSpace {
-		records, err := runAdvisorForNamespace(c, ns)
+		records, err := runAdvisorForNamespace(ctx, c, selector, ns)
 		if err != nil {
-			fmt.Printf("❌ 处理命名空间 %s 失败: %v\n", ns, err)
+			errs = append(errs, fmt.Errorf("处理命名空间 %s 失败: %w", ns, err))
 			continue
 		}
-		
-		totalRecords += len(records)
-		for _, r := range records {
-			writer.Write([]string{
-				r.Namespace,
-				r.Service,
-				fmt.Sprintf("%.2f", r.WeightedRPS),
-				fmt.Sprintf("%.1f", r.P95LatencyMs),
-				strconv.Itoa(r.CPURequest),
-				strconv.Itoa(r.CPULimit),
-				strconv.Itoa(r.MemRequest),
-				strconv.Itoa(r.MemLimit),
-				strconv.Itoa(r.MinReplicas),
-				strconv.Itoa(r.RecommendedReplicas),
-				r.Decision,
-				r.Risk,
-				r.Confidence,
-				r.Reason,
-				r.MetricsWindow,
-				r.GeneratedAt,
-			})
-			successfulRecords++
+		for i := range records {
+			records[i].Cluster = cluster
 		}
+		rows = append(rows, records...)
 	}
 
-	fmt.Printf("✅ resourceAdvisor分析完成，已生成 %s (成功处理 %d/%d 条记录)\n", 
-		filename, successfulRecords, totalRecords)
-	return nil
+	return rows, errors.Join(errs...)
 }
 
 func validateResourceAdvisorConfig(c *config.Config) error {
-	if c.Prometheus == "" {
-		return fmt.Errorf("Prometheus地址不能为空")
-	}
-	if len(c.NameSpace) == 0 {
-		return fmt.Errorf("命名空间列表不能为空")
-	}
-	return nil
+	return config.Validate(c, "resourceAdvisor")
 }
 
 /*
@@ -163,10 +122,10 @@ Namespace 维度
 =====================
 */
 
-func runAdvisorForNamespace(c *config.Config, ns string) ([]AdviceRecord, error) {
+func runAdvisorForNamespace(ctx context.Context, c *config.Config, selector *recommend.Selector, ns string) ([]AdviceRecord, error) {
 	var records []AdviceRecord
 
-	services, err := getExportedServices(c.Prometheus)
+	services, err := getExportedServices(ctx, c.Prometheus)
 	if err != nil {
 		return nil, fmt.Errorf("获取服务列表失败: %w", err)
 	}
@@ -179,9 +138,9 @@ func runAdvisorForNamespace(c *config.Config, ns string) ([]AdviceRecord, error)
 		if !strings.HasSuffix(es, "@kubernetescrd") {
 			continue
 		}
-		r, err := runAdvisorForService(c, ns, es)
+		r, err := runAdvisorForService(ctx, c, selector, ns, es)
 		if err != nil {
-			fmt.Printf("⚠️ 处理服务 %s 失败: %v\n", es, err)
+			fmt.Fprintf(os.Stderr, "⚠️ 处理服务 %s 失败: %v\n", es, err)
 			continue
 		}
 		records = append(records, r)
@@ -196,13 +155,13 @@ Service 维度
 =====================
 */
 
-func runAdvisorForService(c *config.Config, ns, es string) (AdviceRecord, error) {
-	rps, err := queryDailyWeightedRPS(c.Prometheus, es)
+func runAdvisorForService(ctx context.Context, c *config.Config, selector *recommend.Selector, ns, es string) (AdviceRecord, error) {
+	rps, err := queryDailyWeightedRPS(ctx, c.Prometheus, es)
 	if err != nil {
 		return AdviceRecord{}, fmt.Errorf("查询RPS失败: %w", err)
 	}
 	
-	lat, err := queryDailyP95Latency(c.Prometheus, es)
+	lat, err := queryDailyP95Latency(ctx, c.Prometheus, es)
 	if err != nil {
 		return AdviceRecord{}, fmt.Errorf("查询延迟失败: %w", err)
 	}
@@ -212,6 +171,18 @@ func runAdvisorForService(c *config.Config, ns, es string) (AdviceRecord, error)
 	cpuLim := calculateCPULimit(rps, c.ResourceAdvisor.CPULimitFactor)
 	memReq := calculateMemoryRequest(rps, c.ResourceAdvisor.MemRequestFactor)
 	memLim := calculateMemoryLimit(rps, c.ResourceAdvisor.MemLimitFactor)
+	policy := builtinPolicy
+	basis := fmt.Sprintf("cpuRequest=100 + rps * 0.1 * %.1f(=%dm); cpuLimit=(100 + rps * 0.1) * %.1f(=%dm); memRequest=128 + rps * 2 * %.1f(=%dMi); memLimit=(128 + rps * 2) * %.1f(=%dMi)",
+		c.ResourceAdvisor.CPURequestFactor, cpuReq, c.ResourceAdvisor.CPULimitFactor, cpuLim,
+		c.ResourceAdvisor.MemRequestFactor, memReq, c.ResourceAdvisor.MemLimitFactor, memLim)
+
+	// 配置了 recommend 策略时，以 RPS 估算的使用量作为策略输入
+	if r, ok := selector.Lookup(ns, es); ok {
+		advice := r.Recommend(estimateUsage(rps))
+		cpuReq, cpuLim = int(advice.CPURequest), int(advice.CPULimit)
+		memReq, memLim = int(advice.MemRequest), int(advice.MemLimit)
+		policy, basis = advice.Policy, "按 RPS 估算使用量; "+advice.Basis
+	}
 
 	rec := AdviceRecord{
 		Namespace:     ns,
@@ -223,6 +194,8 @@ func runAdvisorForService(c *config.Config, ns, es string) (AdviceRecord, error)
 		MemRequest:    memReq,
 		MemLimit:      memLim,
 		MinReplicas:   replicaMin,
+		Policy:        policy,
+		Basis:         basis,
 		MetricsWindow: "1d",
 		GeneratedAt:   time.Now().Format(time.RFC3339),
 	}
@@ -234,6 +207,14 @@ func runAdvisorForService(c *config.Config, ns, es string) (AdviceRecord, error)
 	return rec, nil
 }
 
+// estimateUsage 按 RPS 估算容器使用量（CPU 100m + rps*0.1，内存 128Mi + rps*2），只有日均值一个读数
+func estimateUsage(rps float64) recommend.Usage {
+	return recommend.Usage{
+		CPU:    []float64{100 + rps*0.1},
+		Memory: []float64{128 + rps*2},
+	}
+}
+
 func calculateCPURequest(rps float64, factor float64) int {
 	base := 100.0 // 基础CPU需求
 	if factor == 0 {
@@ -303,10 +284,10 @@ Prometheus 查询
 =====================
 */
 
-func queryDailyWeightedRPS(promAddr, es string) (float64, error) {
+func queryDailyWeightedRPS(ctx context.Context, promAddr, es string) (float64, error) {
 	query := fmt.Sprintf(`avg_over_time(sum by(method)(rate(traefik_service_requests_total{namespace="traefik",exported_service="%s"}[5m]))[1d:1h])`, es)
 
-	result, err := queryProm(promAddr, query)
+	result, err := queryProm(ctx, promAddr, query)
 	if err != nil {
 		return 0, fmt.Errorf("查询失败: %w", err)
 	}
@@ -326,11 +307,11 @@ func queryDailyWeightedRPS(promAddr, es string) (float64, error) {
 	return total, nil
 }
 
-func queryDailyP95Latency(promAddr, es string) (float64, error) {
+func queryDailyP95Latency(ctx context.Context, promAddr, es string) (float64, error) {
 	// 先查询当前 P95 延迟
 	query := fmt.Sprintf(`histogram_quantile(0.95,sum by(le)(rate(traefik_service_request_duration_seconds_bucket{namespace="traefik",exported_service="%s"}[5m]))) * 1000`, es)
 
-	result, err := queryProm(promAddr, query)
+	result, err := queryProm(ctx, promAddr, query)
 	if err != nil {
 		return 0, fmt.Errorf("查询失败: %w", err)
 	}
@@ -356,13 +337,17 @@ type promResult struct {
 	Value  string
 }
 
-func queryProm(promAddr, q string) ([]promResult, error) {
+func queryProm(ctx context.Context, promAddr, q string) ([]promResult, error) {
 	u := strings.TrimRight(promAddr, "/") + "/api/v1/query"
 	params := url.Values{}
 	params.Set("query", q)
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求失败: %w", err)
+	}
 	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(u + "?" + params.Encode())
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -417,11 +402,15 @@ exported_service 发现
 =====================
 */
 
-func getExportedServices(promAddr string) ([]string, error) {
+func getExportedServices(ctx context.Context, promAddr string) ([]string, error) {
 	u := strings.TrimRight(promAddr, "/") + "/api/v1/label/exported_service/values"
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求失败: %w", err)
+	}
 	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(u)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -0,0 +1,190 @@
+// Package runtimeInspect 通过 exec 采集运行中容器的进程、端口与环境变量
+package runtimeInspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ContainerRuntime 单个容器的运行时快照
+type ContainerRuntime struct {
+	Cluster   string `json:"cluster" header:"Cluster"`
+	Namespace string `json:"namespace" header:"Namespace"`
+	Pod       string `json:"pod" header:"Pod"`
+	Container string `json:"container" header:"Container"`
+	Command   string `json:"command" header:"Command"`
+	Ports     string `json:"ports" header:"Ports"`
+	Processes string `json:"processes" header:"Processes"`
+	Envs      string `json:"envs" header:"Envs"`
+}
+
+// GetRuntimeInspect 在单个集群内运行中的容器里执行只读命令，采集进程、端口和环境变量
+// exec 需要真实的 rest.Config，离线/fake 客户端会直接返回错误
+func GetRuntimeInspect(ctx context.Context, c *config.Config, kc kube.Client) ([]ContainerRuntime, error) {
+	cfg := kc.RESTConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("集群 %s 的客户端不支持 exec，需要连接真实集群", kc.Name())
+	}
+	clientset := kc.Kubernetes()
+
+	var rows []ContainerRuntime
+	var errs []error
+
+	// 限制并发执行，避免对集群造成压力
+	semaphore := make(chan struct{}, 5) // 最多同时执行5个容器检查
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, ns := range c.NameSpace {
+		pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("获取命名空间 %s 的 Pod 失败: %w", ns, err))
+			continue
+		}
+
+		for _, pod := range pods.Items {
+			// 跳过非运行中的 Pod
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+
+			for _, container := range pod.Spec.Containers {
+				wg.Add(1)
+				go func(ns, podName, containerName string, cmd []string) {
+					defer wg.Done()
+					semaphore <- struct{}{}
+					defer func() { <-semaphore }()
+
+					// 执行命令采集运行时信息（带超时控制）
+					processes, err1 := execInPod(ctx, cfg, clientset, ns, podName, containerName, []string{"ps", "aux"})
+					envs, err2 := execInPod(ctx, cfg, clientset, ns, podName, containerName, []string{"printenv"})
+					ports, err3 := execInPod(ctx, cfg, clientset, ns, podName, containerName, []string{"sh", "-c", "ss -tulnp || netstat -tulnp"})
+
+					// 处理错误
+					if err1 != nil {
+						processes = fmt.Sprintf("执行失败: %v", err1)
+					}
+					if err2 != nil {
+						envs = fmt.Sprintf("执行失败: %v", err2)
+					}
+					if err3 != nil {
+						ports = fmt.Sprintf("执行失败: %v", err3)
+					}
+
+					mu.Lock()
+					rows = append(rows, ContainerRuntime{
+						Cluster:   kc.Name(),
+						Namespace: ns,
+						Pod:       podName,
+						Container: containerName,
+						Command:   strings.Join(cmd, " "),
+						Ports:     sanitize(ports),
+						Processes: sanitize(processes),
+						Envs:      sanitize(envs),
+					})
+					mu.Unlock()
+				}(ns, pod.Name, container.Name, container.Command)
+			}
+		}
+	}
+
+	wg.Wait()
+
+	// 并发采集的结果顺序不固定，按命名空间/Pod/容器排序
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		return a.Container < b.Container
+	})
+
+	return rows, errors.Join(errs...)
+}
+
+func execInPod(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, namespace, pod, container string, cmd []string) (string, error) {
+	// 安全检查：只允许执行白名单命令
+	if !isSafeCommand(cmd) {
+		return "", fmt.Errorf("命令不安全: %v", cmd)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req := clientset.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdin:     false,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       false,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("Exec创建失败: %w", err)
+	}
+
+	var stdout, stderr strings.Builder
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return "", fmt.Errorf("执行失败: %w (stderr: %s)", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+func isSafeCommand(cmd []string) bool {
+	safeCommands := map[string]bool{
+		"ps":       true,
+		"aux":      true,
+		"printenv": true,
+		"ss":       true,
+		"-tulnp":   true,
+		"netstat":  true,
+		"sh":       true,
+		"-c":       true,
+	}
+
+	for _, arg := range cmd {
+		if !safeCommands[arg] {
+			// 检查是否包含危险字符
+			if strings.ContainsAny(arg, "&|;`$()<>\"") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\n", " | ")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.TrimSpace(s)
+}
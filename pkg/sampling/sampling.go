@@ -0,0 +1,164 @@
+// Package sampling 在一段时间窗口内多次读取 metrics.k8s.io 的 PodMetrics，
+// 为没有 Prometheus 的集群提供 min/avg/p95/max 统计
+package sampling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// DefaultInterval 默认采样间隔，metrics-server 默认每 15s 抓取一次，更短的间隔只会读到重复数据
+const DefaultInterval = 30 * time.Second
+
+// Options 采样窗口，Duration 为 0 时只读取一次瞬时值
+type Options struct {
+	Duration time.Duration
+	Interval time.Duration
+}
+
+// Enabled 是否为多次采样模式
+func (o Options) Enabled() bool {
+	return o.Duration > 0
+}
+
+// Validate 校验采样参数
+func (o Options) Validate() error {
+	if o.Duration < 0 {
+		return fmt.Errorf("采样时长不能为负数: %s", o.Duration)
+	}
+	if o.Enabled() && (o.Interval <= 0 || o.Interval > o.Duration) {
+		return fmt.Errorf("采样间隔需大于 0 且不超过采样时长: %s", o.Interval)
+	}
+	return nil
+}
+
+// Usage 单个容器一次采样的使用量，CPU 单位为 m，Memory 单位为字节
+type Usage struct {
+	CPU    int64
+	Memory int64
+}
+
+// Sample 单个命名空间一次采样的结果：Pod 名 -> 容器名 -> 使用量
+type Sample map[string]map[string]Usage
+
+// Collect 按 opts 采集各命名空间的 PodMetrics，返回命名空间 -> 按时间顺序排列的采样
+// 个别轮次读取失败时跳过该轮，每个命名空间只保留第一个错误；ctx 取消时返回已采集的数据
+func Collect(ctx context.Context, metricsClient metrics.Interface, namespaces []string, opts Options) (map[string][]Sample, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	samples := make(map[string][]Sample, len(namespaces))
+	failed := make(map[string]error)
+	poll := func() {
+		for _, ns := range namespaces {
+			list, err := metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if failed[ns] == nil {
+					failed[ns] = fmt.Errorf("命名空间 %s 获取 PodMetrics 失败: %w", ns, err)
+				}
+				continue
+			}
+			sample := make(Sample, len(list.Items))
+			for _, pm := range list.Items {
+				containers := make(map[string]Usage, len(pm.Containers))
+				for _, c := range pm.Containers {
+					containers[c.Name] = Usage{CPU: c.Usage.Cpu().MilliValue(), Memory: c.Usage.Memory().Value()}
+				}
+				sample[pm.Name] = containers
+			}
+			samples[ns] = append(samples[ns], sample)
+		}
+	}
+
+	var errs []error
+	poll()
+	if opts.Enabled() {
+		// 首轮立即采集，之后每个间隔采集一次，共 Duration/Interval+1 轮
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+	loop:
+		for i := int64(0); i < int64(opts.Duration/opts.Interval); i++ {
+			select {
+			case <-ctx.Done():
+				errs = append(errs, fmt.Errorf("采样中断，仅使用已采集的 %d 轮数据: %w", rounds(samples), ctx.Err()))
+				break loop
+			case <-ticker.C:
+				poll()
+			}
+		}
+	}
+
+	for _, ns := range namespaces {
+		if err := failed[ns]; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return samples, errors.Join(errs...)
+}
+
+// rounds 返回各命名空间中最多的采样轮数
+func rounds(samples map[string][]Sample) int {
+	n := 0
+	for _, s := range samples {
+		n = max(n, len(s))
+	}
+	return n
+}
+
+// Stats 一组采样值的统计
+type Stats struct {
+	Min int64
+	Avg int64
+	P95 int64
+	Max int64
+}
+
+// Summarize 计算 min/avg/p95/max，p95 取最近秩（nearest-rank）；values 为空时返回零值
+func Summarize(values []int64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	sorted := sortedCopy(values)
+	var total int64
+	for _, v := range sorted {
+		total += v
+	}
+	return Stats{
+		Min: sorted[0],
+		Avg: total / int64(len(sorted)),
+		P95: nearestRank(sorted, 95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// Number Percentile 支持的数值类型，metrics-server 读数为 int64，Prometheus 与策略表达式的读数为 float64
+type Number interface {
+	~int64 | ~float64
+}
+
+// Percentile 返回第 p（0-100）百分位数，取最近秩；values 为空时返回 0
+func Percentile[T Number](values []T, p float64) T {
+	if len(values) == 0 {
+		return 0
+	}
+	return nearestRank(sortedCopy(values), p)
+}
+
+func sortedCopy[T Number](values []T) []T {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func nearestRank[T Number](sorted []T, p float64) T {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
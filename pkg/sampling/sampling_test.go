@@ -0,0 +1,184 @@
+package sampling
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestSummarize(t *testing.T) {
+	seq := func(n int) []int64 {
+		values := make([]int64, n)
+		for i := range values {
+			// 倒序输入，检查排序
+			values[i] = int64((n - i) * 10)
+		}
+		return values
+	}
+	tests := []struct {
+		name   string
+		values []int64
+		want   Stats
+	}{
+		{name: "空窗口", want: Stats{}},
+		{name: "单个采样", values: []int64{42}, want: Stats{Min: 42, Avg: 42, P95: 42, Max: 42}},
+		// 平均值向下取整
+		{name: "两个采样", values: []int64{3, 4}, want: Stats{Min: 3, Avg: 3, P95: 4, Max: 4}},
+		// 20 个采样：ceil(0.95×20)=19，取第 19 个
+		{name: "p95 恰好整除", values: seq(20), want: Stats{Min: 10, Avg: 105, P95: 190, Max: 200}},
+		// 21 个采样：ceil(0.95×21)=ceil(19.95)=20，取第 20 个
+		{name: "p95 向上取秩", values: seq(21), want: Stats{Min: 10, Avg: 110, P95: 200, Max: 210}},
+		// 10 个采样：ceil(9.5)=10，p95 即最大值
+		{name: "采样较少时 p95 为最大值", values: seq(10), want: Stats{Min: 10, Avg: 55, P95: 100, Max: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append([]int64(nil), tt.values...)
+			if got := Summarize(tt.values); got != tt.want {
+				t.Errorf("Summarize(%v) = %+v, want %+v", tt.values, got, tt.want)
+			}
+			if !reflect.DeepEqual(in, tt.values) {
+				t.Errorf("Summarize 修改了输入: %v", tt.values)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{40, 10, 30, 20}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{25, 10},
+		{26, 20},
+		{50, 20},
+		{75, 30},
+		{95, 40},
+		{100, 40},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v, %g) = %g, want %g", values, tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile([]int64(nil), 95); got != 0 {
+		t.Errorf("Percentile(nil, 95) = %d, want 0", got)
+	}
+	if got := Percentile([]int64{7}, 50); got != 7 {
+		t.Errorf("Percentile([7], 50) = %d, want 7", got)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "瞬时值", opts: Options{}},
+		{name: "瞬时值忽略间隔", opts: Options{Interval: time.Hour}},
+		{name: "采样窗口", opts: Options{Duration: 10 * time.Minute, Interval: 30 * time.Second}},
+		{name: "间隔等于时长", opts: Options{Duration: time.Minute, Interval: time.Minute}},
+		{name: "时长为负", opts: Options{Duration: -time.Second}, wantErr: "采样时长不能为负数"},
+		{name: "间隔为 0", opts: Options{Duration: time.Minute}, wantErr: "采样间隔需大于 0"},
+		{name: "间隔超过时长", opts: Options{Duration: time.Minute, Interval: 2 * time.Minute}, wantErr: "采样间隔需大于 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func metricsClient(t *testing.T) *metricsfake.Clientset {
+	t.Helper()
+	client := metricsfake.NewSimpleClientset()
+	gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	pm := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web-a"},
+		Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}}},
+	}
+	if err := client.Tracker().Create(gvr, pm, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCollect(t *testing.T) {
+	want := Sample{"web-a": {"app": {CPU: 250, Memory: 128 * 1024 * 1024}}}
+
+	t.Run("瞬时值", func(t *testing.T) {
+		samples, err := Collect(context.Background(), metricsClient(t), []string{"demo"}, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(samples["demo"], []Sample{want}) {
+			t.Errorf("Collect() = %v, want one sample %v", samples["demo"], want)
+		}
+	})
+
+	t.Run("采样窗口", func(t *testing.T) {
+		// 首轮立即采集，之后每个间隔一轮，共 Duration/Interval+1 轮
+		samples, err := Collect(context.Background(), metricsClient(t), []string{"demo"}, Options{Duration: 30 * time.Millisecond, Interval: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples["demo"]) != 4 {
+			t.Errorf("got %d samples, want 4", len(samples["demo"]))
+		}
+	})
+
+	t.Run("中断时返回已采集的数据", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		samples, err := Collect(ctx, metricsClient(t), []string{"demo"}, Options{Duration: 2 * time.Hour, Interval: time.Hour})
+		if err == nil || !strings.Contains(err.Error(), "采样中断，仅使用已采集的 1 轮数据") {
+			t.Errorf("error = %v, want 采样中断", err)
+		}
+		if len(samples["demo"]) != 1 {
+			t.Errorf("got %d samples, want 1", len(samples["demo"]))
+		}
+	})
+
+	t.Run("个别命名空间失败", func(t *testing.T) {
+		client := metricsClient(t)
+		client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetNamespace() == "broken" {
+				return true, nil, errors.New("boom")
+			}
+			return false, nil, nil
+		})
+		samples, err := Collect(context.Background(), client, []string{"demo", "broken"}, Options{})
+		if err == nil || !strings.Contains(err.Error(), "命名空间 broken 获取 PodMetrics 失败: boom") {
+			t.Errorf("error = %v, want broken 失败", err)
+		}
+		if len(samples["demo"]) != 1 || len(samples["broken"]) != 0 {
+			t.Errorf("samples = %v, want demo only", samples)
+		}
+	})
+}
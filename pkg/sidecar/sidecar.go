@@ -0,0 +1,102 @@
+// Package sidecar 根据配置识别 Pod 中的 sidecar 容器
+package sidecar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8stools/pkg/config"
+)
+
+// DefaultAnnotation 默认的 Pod 注解名，值为逗号分隔的 sidecar 容器名称
+const DefaultAnnotation = "k8stools.io/sidecars"
+
+// DefaultNames 内置的常见 sidecar 容器名称
+var DefaultNames = []string{
+	"istio-proxy",
+	"linkerd-proxy",
+	"envoy",
+	"envoy-sidecar",
+	"fluent-bit",
+	"fluentd",
+	"filebeat",
+	"vault-agent",
+	"cloud-sql-proxy",
+}
+
+// Classifier sidecar 容器识别器
+type Classifier struct {
+	names      map[string]bool
+	patterns   []*regexp.Regexp
+	annotation string
+}
+
+// New 根据配置创建识别器，配置中的名称追加到 DefaultNames
+func New(c config.SidecarConfig) (*Classifier, error) {
+	cl := &Classifier{
+		names:      make(map[string]bool),
+		annotation: c.Annotation,
+	}
+	if cl.annotation == "" {
+		cl.annotation = DefaultAnnotation
+	}
+	for _, n := range DefaultNames {
+		cl.names[n] = true
+	}
+	for _, n := range c.Names {
+		cl.names[n] = true
+	}
+	for _, p := range c.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar 正则 %q 格式错误: %w", p, err)
+		}
+		cl.patterns = append(cl.patterns, re)
+	}
+	return cl, nil
+}
+
+// IsSidecar 判断 Pod 中的普通容器是否为 sidecar：Pod 注解列出、名称匹配或正则匹配
+func (cl *Classifier) IsSidecar(pod *corev1.Pod, container string) bool {
+	if v, ok := pod.Annotations[cl.annotation]; ok {
+		for _, n := range strings.Split(v, ",") {
+			if strings.TrimSpace(n) == container {
+				return true
+			}
+		}
+	}
+	if cl.names[container] {
+		return true
+	}
+	for _, re := range cl.patterns {
+		if re.MatchString(container) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split 将 Pod 中运行的容器分为主容器和 sidecar
+// 原生 sidecar（restartPolicy 为 Always 的 init 容器）归为 sidecar，其余 init 容器运行结束后不占用资源，不参与统计
+func (cl *Classifier) Split(pod *corev1.Pod) (main, sidecars []corev1.Container) {
+	for _, c := range pod.Spec.InitContainers {
+		if IsNativeSidecar(c) {
+			sidecars = append(sidecars, c)
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if cl.IsSidecar(pod, c.Name) {
+			sidecars = append(sidecars, c)
+		} else {
+			main = append(main, c)
+		}
+	}
+	return main, sidecars
+}
+
+// IsNativeSidecar 判断 init 容器是否为原生 sidecar
+func IsNativeSidecar(c corev1.Container) bool {
+	return c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
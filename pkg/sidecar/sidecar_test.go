@@ -0,0 +1,92 @@
+package sidecar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsSidecar(t *testing.T) {
+	cfg := config.SidecarConfig{Names: []string{"log-shipper"}, Patterns: []string{"^otel-", "-exporter$"}}
+	tests := []struct {
+		name        string
+		cfg         config.SidecarConfig
+		annotations map[string]string
+		container   string
+		want        bool
+	}{
+		{name: "内置名称", cfg: cfg, container: "istio-proxy", want: true},
+		{name: "配置的名称", cfg: cfg, container: "log-shipper", want: true},
+		{name: "正则前缀", cfg: cfg, container: "otel-collector", want: true},
+		{name: "正则后缀", cfg: cfg, container: "redis-exporter", want: true},
+		{name: "正则不匹配", cfg: cfg, container: "my-otel", want: false},
+		{name: "主容器", cfg: cfg, container: "app", want: false},
+		{name: "注解列出", cfg: cfg, annotations: map[string]string{DefaultAnnotation: "app, cache"}, container: "cache", want: true},
+		{name: "注解未列出", cfg: cfg, annotations: map[string]string{DefaultAnnotation: "cache"}, container: "app", want: false},
+		// 注解只追加 sidecar，不会把名称或正则匹配的容器改回主容器
+		{name: "注解与名称同时存在", cfg: cfg, annotations: map[string]string{DefaultAnnotation: "cache"}, container: "istio-proxy", want: true},
+		{name: "注解与正则同时存在", cfg: cfg, annotations: map[string]string{DefaultAnnotation: "cache"}, container: "otel-agent", want: true},
+		{name: "自定义注解名", cfg: config.SidecarConfig{Annotation: "example.com/sidecars"}, annotations: map[string]string{"example.com/sidecars": "cache"}, container: "cache", want: true},
+		{name: "自定义注解名时忽略默认注解", cfg: config.SidecarConfig{Annotation: "example.com/sidecars"}, annotations: map[string]string{DefaultAnnotation: "cache"}, container: "cache", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl, err := New(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := cl.IsSidecar(pod, tt.container); got != tt.want {
+				t.Errorf("IsSidecar(%s) = %v, want %v", tt.container, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := New(config.SidecarConfig{Patterns: []string{"("}})
+	if err == nil || !strings.Contains(err.Error(), `sidecar 正则 "(" 格式错误`) {
+		t.Errorf("New() error = %v, want 正则格式错误", err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DefaultAnnotation: "cache"}},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				// 普通 init 容器运行结束后不占用资源，不参与统计
+				{Name: "migrate"},
+				// 原生 sidecar 不论名称都归为 sidecar
+				{Name: "mesh", RestartPolicy: &always},
+				// init 容器名称匹配内置名称但不是原生 sidecar，同样不参与统计
+				{Name: "envoy"},
+			},
+			Containers: []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}, {Name: "cache"}, {Name: "worker"}},
+		},
+	}
+	cl, err := New(config.SidecarConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	main, sidecars := cl.Split(pod)
+	names := func(cs []corev1.Container) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.Name)
+		}
+		return out
+	}
+	if got, want := names(main), []string{"app", "worker"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("main = %v, want %v", got, want)
+	}
+	if got, want := names(sidecars), []string{"mesh", "istio-proxy", "cache"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sidecars = %v, want %v", got, want)
+	}
+}
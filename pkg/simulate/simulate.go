@@ -0,0 +1,378 @@
+// Package simulate 将 paradise/trend 的建议 Requests 替换到现有 Pod 上，
+// 以首次适应递减（first-fit-decreasing）装箱估算所需节点数
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/nodes"
+	"k8stools/pkg/paradise"
+	"k8stools/pkg/trend"
+	"k8stools/pkg/workload"
+)
+
+// 建议来源
+const (
+	SourceParadise = "paradise"
+	SourceTrend    = "trend"
+)
+
+// Sources 支持的建议来源
+var Sources = []string{SourceParadise, SourceTrend}
+
+// ScenarioCurrent 使用现有 Requests 的场景名称
+const ScenarioCurrent = "current"
+
+// Result 单个场景的装箱结果，CPU 单位为 m，内存单位为 MiB
+type Result struct {
+	Cluster           string `json:"cluster" header:"Cluster"`
+	Scenario          string `json:"scenario" header:"Scenario"`
+	Shape             string `json:"shape" header:"Node Shape"`
+	Pods              int    `json:"pods" header:"Pods"`
+	ChangedContainers int    `json:"changedContainers" header:"Changed Containers"`
+	CPURequests       int64  `json:"cpuRequests" header:"CPU Requests (m)"`
+	MemRequests       int64  `json:"memRequests" header:"Mem Requests (Mi)"`
+	Nodes             int    `json:"nodes" header:"Nodes"`
+	NodesSaved        int    `json:"nodesSaved" header:"Nodes Saved"`
+	Unschedulable     int    `json:"unschedulable" header:"Unschedulable"`
+	UnschedulablePods string `json:"unschedulablePods,omitempty" header:"Unschedulable Pods"`
+}
+
+// Recommendation 单个容器的建议 Requests，CPU 单位为 m，内存单位为 MiB
+type Recommendation struct {
+	CPU    int64
+	Memory int64
+}
+
+// Recommendations 容器建议，键见 Key
+type Recommendations map[string]Recommendation
+
+// Key 返回 "命名空间/工作负载/容器"；trend 的建议可能没有 Kind，因此不区分工作负载类型
+func Key(namespace, workload, container string) string {
+	return namespace + "/" + workload + "/" + container
+}
+
+// add 同一容器有多条建议时（trend 按 Pod 输出）取较大值
+func (r Recommendations) add(key string, rec Recommendation) {
+	cur := r[key]
+	r[key] = Recommendation{CPU: max(cur.CPU, rec.CPU), Memory: max(cur.Memory, rec.Memory)}
+}
+
+// FromParadise 将 paradise 的建议转换为容器建议
+func FromParadise(rows []paradise.ResourceAdvice) Recommendations {
+	recs := make(Recommendations)
+	for _, r := range rows {
+		recs.add(Key(r.Namespace, r.Workload, r.Container), Recommendation{CPU: r.CPURequest, Memory: r.MemRequest})
+	}
+	return recs
+}
+
+// FromTrend 将 trend 的建议转换为容器建议
+func FromTrend(rows []trend.TrendAdvice) Recommendations {
+	recs := make(Recommendations)
+	for _, r := range rows {
+		recs.add(Key(r.Namespace, r.Workload, r.Container), Recommendation{CPU: int64(r.CPURequest), Memory: int64(r.MemRequest)})
+	}
+	return recs
+}
+
+// Simulate 分别以现有 Requests 和 recs 替换后的 Requests 对单个集群的 Pod 装箱，返回两个场景的结果
+// 只替换配置的命名空间中有建议的容器，其余 Pod 保持不变；DaemonSet 与静态 Pod 视为每个节点的固定开销，不参与装箱。
+// 节点规格来自配置 simulate（数量不限），未配置时使用集群现有节点（数量固定，遵循 nodeSelector）；
+// 不考虑亲和性、污点容忍与拓扑分布约束
+func Simulate(ctx context.Context, c *config.Config, kc kube.Client, scenario string, recs Recommendations) ([]Result, error) {
+	clientset := kc.Kubernetes()
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Node 失败: %w", err)
+	}
+	podList, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Pod 失败: %w", err)
+	}
+
+	// 配置的命名空间中按 ownerReferences 确定 Pod 所属工作负载，用于匹配建议
+	var errs []error
+	targets := make(map[string]*workload.OwnerResolver, len(c.NameSpace))
+	for _, ns := range c.NameSpace {
+		owners, err := workload.LoadOwnerResolver(ctx, clientset, ns)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+			owners = workload.NewOwnerResolver(nil, nil)
+		}
+		targets[ns] = owners
+	}
+
+	// 节点固定开销：DaemonSet 与静态 Pod 的 Requests
+	overhead := make(map[string]usage)
+	var pods []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if ref := metav1.GetControllerOf(pod); ref != nil && (ref.Kind == workload.KindDaemonSet || ref.Kind == "Node") {
+			if pod.Spec.NodeName != "" {
+				o := overhead[pod.Spec.NodeName]
+				o.add(podUsage(pod))
+				overhead[pod.Spec.NodeName] = o
+			}
+			continue
+		}
+		pods = append(pods, pod)
+	}
+
+	// 替换建议 Requests
+	recommended := make([]*corev1.Pod, len(pods))
+	changed := 0
+	for i, pod := range pods {
+		recommended[i] = pod
+		owners, ok := targets[pod.Namespace]
+		if !ok {
+			continue
+		}
+		owner, ok := owners.Resolve(pod)
+		if !ok {
+			continue
+		}
+		var copied *corev1.Pod
+		for j, ct := range pod.Spec.Containers {
+			rec, ok := recs[Key(pod.Namespace, owner.Name, ct.Name)]
+			if !ok {
+				continue
+			}
+			if copied == nil {
+				copied = pod.DeepCopy()
+			}
+			requests := copied.Spec.Containers[j].Resources.Requests.DeepCopy()
+			if requests == nil {
+				requests = corev1.ResourceList{}
+			}
+			requests[corev1.ResourceCPU] = *resource.NewMilliQuantity(rec.CPU, resource.DecimalSI)
+			requests[corev1.ResourceMemory] = *resource.NewQuantity(rec.Memory*1024*1024, resource.BinarySI)
+			copied.Spec.Containers[j].Resources.Requests = requests
+			changed++
+		}
+		if copied != nil {
+			recommended[i] = copied
+		}
+	}
+
+	f := newFleet(c, nodeList.Items, overhead)
+	current := pack(f, pods)
+	after := pack(f, recommended)
+
+	base := Result{Cluster: kc.Name(), Shape: f.shape, Pods: len(pods)}
+	before := base
+	before.Scenario = ScenarioCurrent
+	current.fill(&before)
+	res := base
+	res.Scenario = scenario
+	res.ChangedContainers = changed
+	after.fill(&res)
+	res.NodesSaved = before.Nodes - res.Nodes
+	return []Result{before, res}, errors.Join(errs...)
+}
+
+// usage 装箱使用的资源量，CPU 单位为 m，内存单位为字节
+type usage struct {
+	cpu, mem, pods int64
+}
+
+func (u *usage) add(o usage) {
+	u.cpu += o.cpu
+	u.mem += o.mem
+	u.pods += o.pods
+}
+
+func (u usage) fits(free usage) bool {
+	return u.cpu <= free.cpu && u.mem <= free.mem && u.pods <= free.pods
+}
+
+func podUsage(pod *corev1.Pod) usage {
+	requests, _ := nodes.PodRequestsAndLimits(pod)
+	return usage{cpu: requests.Cpu().MilliValue(), mem: requests.Memory().Value(), pods: 1}
+}
+
+// bin 装箱中的一个节点，virtual 为按模拟规格新增的节点（没有标签）
+type bin struct {
+	labels  map[string]string
+	free    usage
+	virtual bool
+	used    bool
+}
+
+// fleet 装箱可用的节点：existing 为现有节点，template 不为 nil 时可按该容量不限量新增节点
+type fleet struct {
+	existing []bin
+	template *usage
+	shape    string
+}
+
+// newFleet 配置了 simulate.nodeCpu/nodeMemory 时使用模拟规格并扣除现有节点中最大的固定开销，
+// 否则使用现有可调度节点，各节点扣除自身的固定开销
+func newFleet(c *config.Config, nodeList []corev1.Node, overhead map[string]usage) fleet {
+	if c.Simulate.NodeCPU > 0 && c.Simulate.NodeMemory > 0 {
+		podsPerNode := int64(c.Simulate.NodePods)
+		if podsPerNode == 0 {
+			podsPerNode = config.DefaultNodePods
+		}
+		var maxOverhead usage
+		for _, o := range overhead {
+			maxOverhead = usage{cpu: max(maxOverhead.cpu, o.cpu), mem: max(maxOverhead.mem, o.mem), pods: max(maxOverhead.pods, o.pods)}
+		}
+		return fleet{
+			template: &usage{
+				cpu:  int64(c.Simulate.NodeCPU*1000) - maxOverhead.cpu,
+				mem:  int64(c.Simulate.NodeMemory*1024*1024*1024) - maxOverhead.mem,
+				pods: podsPerNode - maxOverhead.pods,
+			},
+			shape: fmt.Sprintf("%gC/%gGi/%d pods", c.Simulate.NodeCPU, c.Simulate.NodeMemory, podsPerNode),
+		}
+	}
+
+	// 现有节点按容量从大到小排列，装箱时优先填满大节点；不可调度的节点不参与
+	var schedulable []corev1.Node
+	for _, n := range nodeList {
+		if !n.Spec.Unschedulable {
+			schedulable = append(schedulable, n)
+		}
+	}
+	sort.SliceStable(schedulable, func(i, j int) bool {
+		ci, cj := schedulable[i].Status.Allocatable.Cpu(), schedulable[j].Status.Allocatable.Cpu()
+		if cmp := ci.Cmp(*cj); cmp != 0 {
+			return cmp > 0
+		}
+		mi, mj := schedulable[i].Status.Allocatable.Memory(), schedulable[j].Status.Allocatable.Memory()
+		if cmp := mi.Cmp(*mj); cmp != 0 {
+			return cmp > 0
+		}
+		return schedulable[i].Name < schedulable[j].Name
+	})
+	f := fleet{shape: fmt.Sprintf("cluster (%d nodes)", len(schedulable))}
+	for _, n := range schedulable {
+		o := overhead[n.Name]
+		f.existing = append(f.existing, bin{
+			labels: n.Labels,
+			free: usage{
+				cpu:  n.Status.Allocatable.Cpu().MilliValue() - o.cpu,
+				mem:  n.Status.Allocatable.Memory().Value() - o.mem,
+				pods: n.Status.Allocatable.Pods().Value() - o.pods,
+			},
+		})
+	}
+	return f
+}
+
+// packResult 装箱结果
+type packResult struct {
+	requests      usage
+	nodes         int
+	unschedulable []string
+}
+
+func (p packResult) fill(r *Result) {
+	r.CPURequests = p.requests.cpu
+	r.MemRequests = p.requests.mem / (1024 * 1024)
+	r.Nodes = p.nodes
+	r.Unschedulable = len(p.unschedulable)
+	r.UnschedulablePods = strings.Join(p.unschedulable, ", ")
+}
+
+// pack 首次适应递减装箱：Pod 按主导资源占比从大到小排序，依次放入第一个放得下的节点，
+// 都放不下时按模拟规格开新节点，没有模拟规格或新节点也放不下时记为不可调度
+func pack(f fleet, pods []*corev1.Pod) packResult {
+	bins := make([]*bin, len(f.existing))
+	for i := range f.existing {
+		b := f.existing[i]
+		bins[i] = &b
+	}
+
+	// 以最大节点容量为基准计算主导资源占比
+	var ref usage
+	if f.template != nil {
+		ref = *f.template
+	}
+	for _, b := range bins {
+		ref = usage{cpu: max(ref.cpu, b.free.cpu), mem: max(ref.mem, b.free.mem), pods: max(ref.pods, b.free.pods)}
+	}
+	share := func(u usage) float64 {
+		var s float64
+		if ref.cpu > 0 {
+			s = max(s, float64(u.cpu)/float64(ref.cpu))
+		}
+		if ref.mem > 0 {
+			s = max(s, float64(u.mem)/float64(ref.mem))
+		}
+		return s
+	}
+
+	type item struct {
+		name     string
+		selector labels.Selector
+		usage    usage
+		share    float64
+	}
+	items := make([]item, 0, len(pods))
+	var res packResult
+	for _, pod := range pods {
+		u := podUsage(pod)
+		res.requests.add(u)
+		items = append(items, item{
+			name:     pod.Namespace + "/" + pod.Name,
+			selector: labels.SelectorFromSet(pod.Spec.NodeSelector),
+			usage:    u,
+			share:    share(u),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].share != items[j].share {
+			return items[i].share > items[j].share
+		}
+		return items[i].name < items[j].name
+	})
+
+	for _, it := range items {
+		placed := false
+		for _, b := range bins {
+			// 模拟节点没有标签，不检查 nodeSelector
+			if !b.virtual && !it.selector.Matches(labels.Set(b.labels)) {
+				continue
+			}
+			if it.usage.fits(b.free) {
+				b.free = usage{cpu: b.free.cpu - it.usage.cpu, mem: b.free.mem - it.usage.mem, pods: b.free.pods - it.usage.pods}
+				b.used = true
+				placed = true
+				break
+			}
+		}
+		if !placed && f.template != nil {
+			if b := (&bin{free: *f.template, virtual: true}); it.usage.fits(b.free) {
+				b.free = usage{cpu: b.free.cpu - it.usage.cpu, mem: b.free.mem - it.usage.mem, pods: b.free.pods - it.usage.pods}
+				b.used = true
+				bins = append(bins, b)
+				placed = true
+			}
+		}
+		if !placed {
+			res.unschedulable = append(res.unschedulable, it.name)
+		}
+	}
+
+	for _, b := range bins {
+		if b.used {
+			res.nodes++
+		}
+	}
+	return res
+}
@@ -0,0 +1,144 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func node(name, cpu, mem string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "general"}},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(mem),
+			corev1.ResourcePods:   resource.MustParse("110"),
+		}},
+	}
+}
+
+// pod 构造单容器 Pod，ownerKind 为空表示没有控制器
+func pod(ns, name, ownerKind, owner, nodeName, cpu, mem string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			}}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ownerKind != "" {
+		isController := true
+		p.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: owner, UID: types.UID(owner), Controller: &isController}}
+	}
+	return p
+}
+
+// baseObjects 两个 4C/8Gi 节点，各运行一个 500m/512Mi 的 DaemonSet Pod；demo 下 Deployment web 有 6 个 1C/1Gi 的 Pod
+func baseObjects() []runtime.Object {
+	isController := true
+	objects := []runtime.Object{
+		node("n1", "4", "8Gi", false),
+		node("n2", "4", "8Gi", false),
+		pod("kube-system", "agent-n1", "DaemonSet", "agent", "n1", "500m", "512Mi"),
+		pod("kube-system", "agent-n2", "DaemonSet", "agent", "n2", "500m", "512Mi"),
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "demo", Name: "web-7d9f", UID: "web-7d9f",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "web", Controller: &isController}},
+		}},
+	}
+	for i := 0; i < 6; i++ {
+		objects = append(objects, pod("demo", fmt.Sprintf("web-7d9f-%d", i), "ReplicaSet", "web-7d9f", "", "1", "1Gi"))
+	}
+	return objects
+}
+
+func TestSimulate(t *testing.T) {
+	recs := Recommendations{Key("demo", "web", "app"): {CPU: 300, Memory: 256}}
+	pinned := pod("demo", "pinned", "", "", "", "100m", "128Mi")
+	pinned.Spec.NodeSelector = map[string]string{"pool": "gpu"}
+	finished := pod("demo", "finished", "", "", "", "100", "1Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+
+	tests := []struct {
+		name     string
+		simulate config.SimulateConfig
+		extra    []runtime.Object
+		want     [2]Result
+	}{
+		{
+			// 每个节点扣除 DaemonSet 开销后剩 3500m，现有 Requests 每节点放 3 个 Pod
+			name: "现有节点",
+			want: [2]Result{
+				{Scenario: ScenarioCurrent, Shape: "cluster (2 nodes)", Pods: 6, CPURequests: 6000, MemRequests: 6144, Nodes: 2},
+				{Scenario: "paradise", Shape: "cluster (2 nodes)", Pods: 6, ChangedContainers: 6, CPURequests: 1800, MemRequests: 1536, Nodes: 1, NodesSaved: 1},
+			},
+		},
+		{
+			// 模拟节点扣除最大的 DaemonSet 开销后剩 1500m，按需新增
+			name:     "模拟节点",
+			simulate: config.SimulateConfig{NodeCPU: 2, NodeMemory: 4},
+			want: [2]Result{
+				{Scenario: ScenarioCurrent, Shape: "2C/4Gi/110 pods", Pods: 6, CPURequests: 6000, MemRequests: 6144, Nodes: 6},
+				{Scenario: "paradise", Shape: "2C/4Gi/110 pods", Pods: 6, ChangedContainers: 6, CPURequests: 1800, MemRequests: 1536, Nodes: 2, NodesSaved: 4},
+			},
+		},
+		{
+			// 已封锁的节点不参与装箱；nodeSelector 不匹配或超出节点容量的 Pod 不可调度；已结束的 Pod 不计入
+			name:  "不可调度",
+			extra: []runtime.Object{node("n3", "16", "32Gi", true), pod("demo", "big", "", "", "", "8", "1Gi"), pinned, finished},
+			want: [2]Result{
+				{Scenario: ScenarioCurrent, Shape: "cluster (2 nodes)", Pods: 8, CPURequests: 14100, MemRequests: 7296, Nodes: 2,
+					Unschedulable: 2, UnschedulablePods: "demo/big, demo/pinned"},
+				{Scenario: "paradise", Shape: "cluster (2 nodes)", Pods: 8, ChangedContainers: 6, CPURequests: 9900, MemRequests: 2688, Nodes: 1, NodesSaved: 1,
+					Unschedulable: 2, UnschedulablePods: "demo/big, demo/pinned"},
+			},
+		},
+		{
+			// 模拟节点不检查 nodeSelector，但超出单个节点容量的 Pod 仍不可调度
+			name:     "模拟节点放不下",
+			simulate: config.SimulateConfig{NodeCPU: 2, NodeMemory: 4},
+			extra:    []runtime.Object{pod("demo", "big", "", "", "", "8", "1Gi"), pinned},
+			want: [2]Result{
+				{Scenario: ScenarioCurrent, Shape: "2C/4Gi/110 pods", Pods: 8, CPURequests: 14100, MemRequests: 7296, Nodes: 6,
+					Unschedulable: 1, UnschedulablePods: "demo/big"},
+				{Scenario: "paradise", Shape: "2C/4Gi/110 pods", Pods: 8, ChangedContainers: 6, CPURequests: 9900, MemRequests: 2688, Nodes: 2, NodesSaved: 4,
+					Unschedulable: 1, UnschedulablePods: "demo/big"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := kube.NewForClients("test", fake.NewSimpleClientset(append(baseObjects(), tt.extra...)...), nil, nil)
+			c := &config.Config{NameSpace: []string{"demo"}, Simulate: tt.simulate}
+			got, err := Simulate(context.Background(), c, kc, "paradise", recs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("got %d results, want 2", len(got))
+			}
+			for i := range got {
+				tt.want[i].Cluster = "test"
+				if got[i] != tt.want[i] {
+					t.Errorf("%s =\n%+v\nwant\n%+v", tt.want[i].Scenario, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package snapshot_test
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8stools/pkg/config"
+	"k8stools/pkg/cpu"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/paradise"
+	"k8stools/pkg/sampling"
+	"k8stools/pkg/snapshot"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+var update = flag.Bool("update", false, "按 fixture 重新生成 testdata/demo.json.gz")
+
+const testdata = "testdata/demo.json.gz"
+
+func resources(cpuReq, memReq, cpuLim, memLim string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuReq), corev1.ResourceMemory: resource.MustParse(memReq)},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuLim), corev1.ResourceMemory: resource.MustParse(memLim)},
+	}
+}
+
+func usage(cpu, mem string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)}
+}
+
+// fixture 命名空间 demo 下一个带 HPA 的 Deployment（2 个 Pod，含 istio-proxy）、一个 StatefulSet，
+// 以及 LimitRange、ResourceQuota 和一个节点
+func fixture() snapshot.Cluster {
+	isController := true
+	replicas := int32(2)
+	controller := func(kind, name, uid string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: types.UID(uid), Controller: &isController}}
+	}
+	spec := corev1.PodSpec{
+		NodeName: "node-1",
+		Containers: []corev1.Container{
+			{Name: "app", Resources: resources("200m", "256Mi", "1", "512Mi")},
+			{Name: "istio-proxy", Resources: resources("100m", "128Mi", "200m", "256Mi")},
+		},
+	}
+	pod := func(name, kind, owner, uid string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: name, OwnerReferences: controller(kind, owner, uid)},
+			Spec:       spec,
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	podMetrics := func(name, appCPU, appMem string) metricsv1beta1.PodMetrics {
+		return metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: name},
+			Containers: []metricsv1beta1.ContainerMetrics{
+				{Name: "app", Usage: usage(appCPU, appMem)},
+				{Name: "istio-proxy", Usage: usage("10m", "50Mi")},
+			},
+		}
+	}
+
+	return snapshot.Cluster{
+		Name:             "test",
+		Namespaces:       []string{"demo"},
+		NamespaceObjects: []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "demo", Labels: map[string]string{"team": "web"}}}},
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web", UID: "d-web"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: spec},
+			},
+		}},
+		ReplicaSets: []appsv1.ReplicaSet{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web-7d9f", UID: "rs-web", OwnerReferences: controller("Deployment", "web", "d-web")},
+		}},
+		StatefulSets: []appsv1.StatefulSet{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "db", UID: "s-db"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: spec},
+			},
+		}},
+		Pods: []corev1.Pod{
+			pod("web-7d9f-a", "ReplicaSet", "web-7d9f", "rs-web"),
+			pod("web-7d9f-b", "ReplicaSet", "web-7d9f", "rs-web"),
+			pod("db-0", "StatefulSet", "db", "s-db"),
+		},
+		HPAs: []autoscalingv2.HorizontalPodAutoscaler{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				MaxReplicas:    4,
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 2},
+		}},
+		PodMetrics: []metricsv1beta1.PodMetrics{
+			podMetrics("web-7d9f-a", "150m", "300Mi"),
+			podMetrics("web-7d9f-b", "250m", "200Mi"),
+			podMetrics("db-0", "30m", "100Mi"),
+		},
+		LimitRanges: []corev1.LimitRange{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "limits"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type: corev1.LimitTypeContainer,
+				Min:  usage("120m", "96Mi"),
+			}}},
+		}},
+		ResourceQuotas: []corev1.ResourceQuota{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "compute"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{"requests.cpu": resource.MustParse("2")}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{"requests.cpu": resource.MustParse("2")},
+				Used: corev1.ResourceList{"requests.cpu": resource.MustParse("900m")},
+			},
+		}},
+		Nodes: []corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")}},
+		}},
+		NodeMetrics: []metricsv1beta1.NodeMetrics{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage:      usage("1", "3Gi"),
+		}},
+	}
+}
+
+func loadTestdata(t *testing.T) kube.Client {
+	t.Helper()
+	if *update {
+		archive := &snapshot.Archive{Version: snapshot.FormatVersion, CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Clusters: []snapshot.Cluster{fixture()}}
+		if err := snapshot.Save(testdata, archive); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive, err := snapshot.Load(testdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients, err := snapshot.Clients(archive, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clients[0]
+}
+
+type report struct {
+	cpu      []cpu.DeploymentCPU
+	paradise []paradise.ResourceAdvice
+}
+
+func run(t *testing.T, kc kube.Client) report {
+	t.Helper()
+	c := &config.Config{NameSpace: []string{"demo"}}
+	cpuRows, err := cpu.GetDeploymentCpu(context.Background(), c, kc, sampling.Options{})
+	if err != nil {
+		t.Fatalf("cpu: %v", err)
+	}
+	advice, err := paradise.GetParadise(context.Background(), c, kc, sampling.Options{})
+	if err != nil {
+		t.Fatalf("paradise: %v", err)
+	}
+	return report{cpuRows, advice}
+}
+
+// TestReplay 基于 testdata 中的快照运行 cpu 与 paradise
+func TestReplay(t *testing.T) {
+	r := run(t, loadTestdata(t))
+
+	if len(r.cpu) != 2 {
+		t.Fatalf("cpu: got %d rows, want 2", len(r.cpu))
+	}
+	web := r.cpu[0]
+	if web.Kind != "Deployment" || web.Workload != "web" {
+		t.Fatalf("cpu: first row %s/%s, want Deployment/web", web.Kind, web.Workload)
+	}
+	if web.MainCPUUsage != 400 || web.SidecarCPUUsage != 20 || web.MainCPURequests != 400 || web.SidecarCPURequests != 200 {
+		t.Errorf("cpu: web usage/requests = %d/%d %d/%d, want 400/20 400/200",
+			web.MainCPUUsage, web.SidecarCPUUsage, web.MainCPURequests, web.SidecarCPURequests)
+	}
+	if web.HPA != "web" || web.MaxReplicas != 4 {
+		t.Errorf("cpu: web HPA = %q max %d, want web max 4", web.HPA, web.MaxReplicas)
+	}
+
+	// 建议值低于 LimitRange 最小值 120m/96Mi 的部分被修正
+	type values struct{ cpuRequest, cpuLimit, memRequest, memLimit int64 }
+	want := map[string]values{
+		"web/app":         {120, 200, 200, 375},
+		"web/istio-proxy": {120, 120, 96, 128},
+		"db/app":          {120, 120, 96, 150},
+		"db/istio-proxy":  {120, 120, 96, 128},
+	}
+	if len(r.paradise) != len(want) {
+		t.Fatalf("paradise: got %d rows, want %d", len(r.paradise), len(want))
+	}
+	for _, a := range r.paradise {
+		key := a.Workload + "/" + a.Container
+		got := values{a.CPURequest, a.CPULimit, a.MemRequest, a.MemLimit}
+		if got != want[key] {
+			t.Errorf("paradise: %s = %+v, want %+v", key, got, want[key])
+		}
+		if !strings.Contains(a.Compliance, "LimitRange limits") {
+			t.Errorf("paradise: %s 合规说明 = %q, want LimitRange 修正", key, a.Compliance)
+		}
+	}
+}
+
+// TestRoundTrip 从快照回放的客户端重新采集并保存，再次加载后的报表应与原快照一致
+func TestRoundTrip(t *testing.T) {
+	kc := loadTestdata(t)
+
+	captured, warnings, err := snapshot.Capture(context.Background(), kc, []string{"demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Fatalf("Capture warnings: %v", warnings)
+	}
+
+	path := filepath.Join(t.TempDir(), "snap.json.gz")
+	if err := snapshot.Save(path, &snapshot.Archive{Version: snapshot.FormatVersion, CreatedAt: time.Now(), Clusters: []snapshot.Cluster{*captured}}); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := snapshot.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients, err := snapshot.Clients(archive, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := fixture()
+	got := archive.Clusters[0]
+	counts := map[string][2]int{
+		"Deployments":    {len(got.Deployments), len(want.Deployments)},
+		"StatefulSets":   {len(got.StatefulSets), len(want.StatefulSets)},
+		"ReplicaSets":    {len(got.ReplicaSets), len(want.ReplicaSets)},
+		"Pods":           {len(got.Pods), len(want.Pods)},
+		"HPAs":           {len(got.HPAs), len(want.HPAs)},
+		"PodMetrics":     {len(got.PodMetrics), len(want.PodMetrics)},
+		"LimitRanges":    {len(got.LimitRanges), len(want.LimitRanges)},
+		"ResourceQuotas": {len(got.ResourceQuotas), len(want.ResourceQuotas)},
+		"Nodes":          {len(got.Nodes), len(want.Nodes)},
+		"NodeMetrics":    {len(got.NodeMetrics), len(want.NodeMetrics)},
+	}
+	for name, n := range counts {
+		if n[0] != n[1] {
+			t.Errorf("%s: got %d, want %d", name, n[0], n[1])
+		}
+	}
+
+	if before, after := run(t, kc), run(t, clients[0]); !reflect.DeepEqual(before, after) {
+		t.Errorf("回放结果不一致:\nbefore %+v\nafter  %+v", before, after)
+	}
+	if _, err := snapshot.Clients(archive, "other"); err == nil {
+		t.Error("Clients 指定不存在的集群应返回错误")
+	}
+}
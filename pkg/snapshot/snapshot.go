@@ -0,0 +1,355 @@
+// Package snapshot 将分析器读取的集群对象导出为离线归档，并可基于归档构建 fake 客户端回放
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	"k8stools/pkg/kube"
+)
+
+// FormatVersion 归档格式版本，结构不兼容变更时递增
+const FormatVersion = 1
+
+// Archive 快照归档，一个归档可包含多个集群
+type Archive struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Clusters  []Cluster `json:"clusters"`
+}
+
+// Cluster 单个集群在采集时刻的对象
+type Cluster struct {
+	Name       string   `json:"name"`
+	Namespaces []string `json:"namespaces"`
+	// NamespaceObjects 命名空间对象（含标签），回放时用于解析通配符和 namespaceSelector
+	NamespaceObjects []corev1.Namespace   `json:"namespaceObjects,omitempty"`
+	Deployments      []appsv1.Deployment  `json:"deployments"`
+	StatefulSets     []appsv1.StatefulSet `json:"statefulSets,omitempty"`
+	DaemonSets       []appsv1.DaemonSet   `json:"daemonSets,omitempty"`
+	ReplicaSets      []appsv1.ReplicaSet  `json:"replicaSets,omitempty"`
+	Jobs             []batchv1.Job        `json:"jobs,omitempty"`
+	CronJobs         []batchv1.CronJob    `json:"cronJobs,omitempty"`
+	Pods             []corev1.Pod         `json:"pods"`
+	// HPAs 按 autoscaling/v2 保存，早期归档中的 autoscaling/v1 HPA 仍可读取副本范围与状态，但不含目标指标
+	HPAs       []autoscalingv2.HorizontalPodAutoscaler `json:"hpas"`
+	PodMetrics []metricsv1beta1.PodMetrics             `json:"podMetrics"`
+	Events     []corev1.Event                          `json:"events"`
+	// LimitRanges、ResourceQuotas 供 quota 报表及 paradise/trend 的合规检查使用
+	LimitRanges    []corev1.LimitRange    `json:"limitRanges,omitempty"`
+	ResourceQuotas []corev1.ResourceQuota `json:"resourceQuotas,omitempty"`
+	// Nodes、NodeMetrics 为集群级对象，供 nodes 报表使用；节点上的 Pod 只包含已采集命名空间中的部分
+	Nodes       []corev1.Node                `json:"nodes,omitempty"`
+	NodeMetrics []metricsv1beta1.NodeMetrics `json:"nodeMetrics,omitempty"`
+}
+
+// Capture 采集单个集群指定命名空间下分析器所需的对象
+// Deployment/Pod 获取失败直接返回错误；其他工作负载、HPA、PodMetrics、Event、LimitRange、ResourceQuota、Node 属于可选数据，失败时以警告形式返回
+func Capture(ctx context.Context, kc kube.Client, namespaces []string) (*Cluster, []error, error) {
+	clientset := kc.Kubernetes()
+	snap := &Cluster{Name: kc.Name(), Namespaces: namespaces}
+	var warnings []error
+
+	for _, ns := range namespaces {
+		nsObj, err := clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 失败: %w", ns, err))
+		} else {
+			nsObj.ManagedFields = nil
+			snap.NamespaceObjects = append(snap.NamespaceObjects, *nsObj)
+		}
+
+		deployments, err := clientset.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, warnings, fmt.Errorf("获取命名空间 %s 的 Deployments 失败: %w", ns, err)
+		}
+		for _, d := range deployments.Items {
+			d.ManagedFields = nil
+			snap.Deployments = append(snap.Deployments, d)
+		}
+
+		statefulSets, err := clientset.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 StatefulSets 失败: %w", ns, err))
+		} else {
+			for _, o := range statefulSets.Items {
+				o.ManagedFields = nil
+				snap.StatefulSets = append(snap.StatefulSets, o)
+			}
+		}
+
+		daemonSets, err := clientset.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 DaemonSets 失败: %w", ns, err))
+		} else {
+			for _, o := range daemonSets.Items {
+				o.ManagedFields = nil
+				snap.DaemonSets = append(snap.DaemonSets, o)
+			}
+		}
+
+		replicaSets, err := clientset.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 ReplicaSets 失败: %w", ns, err))
+		} else {
+			for _, o := range replicaSets.Items {
+				o.ManagedFields = nil
+				snap.ReplicaSets = append(snap.ReplicaSets, o)
+			}
+		}
+
+		jobs, err := clientset.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 Jobs 失败: %w", ns, err))
+		} else {
+			for _, o := range jobs.Items {
+				o.ManagedFields = nil
+				snap.Jobs = append(snap.Jobs, o)
+			}
+		}
+
+		cronJobs, err := clientset.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 CronJobs 失败: %w", ns, err))
+		} else {
+			for _, o := range cronJobs.Items {
+				o.ManagedFields = nil
+				snap.CronJobs = append(snap.CronJobs, o)
+			}
+		}
+
+		pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, warnings, fmt.Errorf("获取命名空间 %s 的 Pods 失败: %w", ns, err)
+		}
+		for _, p := range pods.Items {
+			p.ManagedFields = nil
+			snap.Pods = append(snap.Pods, p)
+		}
+
+		hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 HPA 失败: %w", ns, err))
+		} else {
+			for _, h := range hpas.Items {
+				h.ManagedFields = nil
+				snap.HPAs = append(snap.HPAs, h)
+			}
+		}
+
+		podMetrics, err := kc.Metrics().MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 PodMetrics 失败: %w", ns, err))
+		} else {
+			for _, m := range podMetrics.Items {
+				m.ManagedFields = nil
+				snap.PodMetrics = append(snap.PodMetrics, m)
+			}
+		}
+
+		events, err := clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 Events 失败: %w", ns, err))
+		} else {
+			for _, e := range events.Items {
+				e.ManagedFields = nil
+				snap.Events = append(snap.Events, e)
+			}
+		}
+
+		limitRanges, err := clientset.CoreV1().LimitRanges(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 LimitRange 失败: %w", ns, err))
+		} else {
+			for _, o := range limitRanges.Items {
+				o.ManagedFields = nil
+				snap.LimitRanges = append(snap.LimitRanges, o)
+			}
+		}
+
+		quotas, err := clientset.CoreV1().ResourceQuotas(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("获取命名空间 %s 的 ResourceQuota 失败: %w", ns, err))
+		} else {
+			for _, o := range quotas.Items {
+				o.ManagedFields = nil
+				snap.ResourceQuotas = append(snap.ResourceQuotas, o)
+			}
+		}
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		warnings = append(warnings, fmt.Errorf("获取 Node 失败: %w", err))
+	} else {
+		for _, n := range nodes.Items {
+			n.ManagedFields = nil
+			snap.Nodes = append(snap.Nodes, n)
+		}
+	}
+
+	nodeMetrics, err := kc.Metrics().MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		warnings = append(warnings, fmt.Errorf("获取 NodeMetrics 失败: %w", err))
+	} else {
+		for _, m := range nodeMetrics.Items {
+			m.ManagedFields = nil
+			snap.NodeMetrics = append(snap.NodeMetrics, m)
+		}
+	}
+
+	return snap, warnings, nil
+}
+
+// Save 将归档写入 gzip 压缩的 JSON 文件
+func Save(path string, archive *Archive) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	// 关闭文件时才可能发现写入失败（如磁盘已满），错误与写入错误一并返回
+	defer func() {
+		if cerr := file.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("关闭快照文件失败: %w", cerr))
+		}
+	}()
+
+	gz := gzip.NewWriter(file)
+	if err := json.NewEncoder(gz).Encode(archive); err != nil {
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	// gzip 在 Close 时写出剩余数据与校验尾部
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	return nil
+}
+
+// Load 读取 Save 生成的归档
+func Load(path string) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开快照文件失败: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("解压快照失败: %w", err)
+	}
+	defer gz.Close()
+
+	var archive Archive
+	if err := json.NewDecoder(gz).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("解析快照失败: %w", err)
+	}
+	if archive.Version != FormatVersion {
+		return nil, fmt.Errorf("不支持的快照版本 %d（当前版本 %d）", archive.Version, FormatVersion)
+	}
+	return &archive, nil
+}
+
+// Clients 为归档中的集群构建基于 fake clientset 的客户端，name 不为空时只返回同名集群
+func Clients(archive *Archive, name string) ([]kube.Client, error) {
+	var clients []kube.Client
+	for i := range archive.Clusters {
+		cl := &archive.Clusters[i]
+		if name != "" && cl.Name != name {
+			continue
+		}
+		kc, err := cl.Client()
+		if err != nil {
+			return nil, fmt.Errorf("集群 %s: %w", cl.Name, err)
+		}
+		clients = append(clients, kc)
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("快照中没有集群 %q", name)
+	}
+	return clients, nil
+}
+
+// Client 基于快照对象构建离线客户端，RESTConfig 为 nil，不支持 exec
+func (cl *Cluster) Client() (kube.Client, error) {
+	var objects []runtime.Object
+	for i := range cl.NamespaceObjects {
+		objects = append(objects, &cl.NamespaceObjects[i])
+	}
+	if len(cl.NamespaceObjects) == 0 {
+		// 早期快照没有命名空间对象，按名称补齐
+		for _, ns := range cl.Namespaces {
+			objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+		}
+	}
+	for i := range cl.Deployments {
+		objects = append(objects, &cl.Deployments[i])
+	}
+	for i := range cl.StatefulSets {
+		objects = append(objects, &cl.StatefulSets[i])
+	}
+	for i := range cl.DaemonSets {
+		objects = append(objects, &cl.DaemonSets[i])
+	}
+	for i := range cl.ReplicaSets {
+		objects = append(objects, &cl.ReplicaSets[i])
+	}
+	for i := range cl.Jobs {
+		objects = append(objects, &cl.Jobs[i])
+	}
+	for i := range cl.CronJobs {
+		objects = append(objects, &cl.CronJobs[i])
+	}
+	for i := range cl.Pods {
+		objects = append(objects, &cl.Pods[i])
+	}
+	for i := range cl.HPAs {
+		objects = append(objects, &cl.HPAs[i])
+	}
+	for i := range cl.Events {
+		objects = append(objects, &cl.Events[i])
+	}
+	for i := range cl.LimitRanges {
+		objects = append(objects, &cl.LimitRanges[i])
+	}
+	for i := range cl.ResourceQuotas {
+		objects = append(objects, &cl.ResourceQuotas[i])
+	}
+	for i := range cl.Nodes {
+		objects = append(objects, &cl.Nodes[i])
+	}
+	kubeClient := fake.NewSimpleClientset(objects...)
+
+	// metrics 的 fake clientset 以 "pods"/"nodes" 作为 PodMetrics/NodeMetrics 的资源名，
+	// NewSimpleClientset 推断出的 "podmetricses" 无法被 List 查到，这里直接写入 tracker
+	metricsClient := metricsfake.NewSimpleClientset()
+	podMetricsResource := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	for i := range cl.PodMetrics {
+		m := &cl.PodMetrics[i]
+		if err := metricsClient.Tracker().Create(podMetricsResource, m, m.Namespace); err != nil {
+			return nil, fmt.Errorf("加载 PodMetrics %s/%s 失败: %w", m.Namespace, m.Name, err)
+		}
+	}
+	nodeMetricsResource := metricsv1beta1.SchemeGroupVersion.WithResource("nodes")
+	for i := range cl.NodeMetrics {
+		m := &cl.NodeMetrics[i]
+		if err := metricsClient.Tracker().Create(nodeMetricsResource, m, ""); err != nil {
+			return nil, fmt.Errorf("加载 NodeMetrics %s 失败: %w", m.Name, err)
+		}
+	}
+
+	return kube.NewForClients(cl.Name, kubeClient, metricsClient, nil), nil
+}
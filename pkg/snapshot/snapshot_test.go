@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"k8stools/pkg/kube"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// TestCaptureOptionalFailures HPA、Event、PodMetrics、NodeMetrics 获取失败时以警告返回，其余对象照常采集
+func TestCaptureOptionalFailures(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "web-a"}},
+	)
+	fail := func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	}
+	for _, resource := range []string{"horizontalpodautoscalers", "events"} {
+		kubeClient.PrependReactor("list", resource, fail)
+	}
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "*", fail)
+
+	snap, warnings, err := Capture(context.Background(), kube.NewForClients("test", kubeClient, metricsClient, nil), []string{"demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(warnings) != 4 {
+		t.Errorf("got %d warnings, want 4: %v", len(warnings), warnings)
+	}
+	if len(snap.Deployments) != 1 || len(snap.Pods) != 1 {
+		t.Errorf("got %d deployments, %d pods, want 1, 1", len(snap.Deployments), len(snap.Pods))
+	}
+}
+
+// TestSaveWriteError 写入失败（/dev/full 模拟磁盘已满）时 Save 返回错误，而不是留下损坏的快照
+func TestSaveWriteError(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("需要 /dev/full")
+	}
+	err := Save("/dev/full", &Archive{Version: FormatVersion})
+	if err == nil || !strings.Contains(err.Error(), "写入快照失败") {
+		t.Errorf("Save() error = %v, want 写入快照失败", err)
+	}
+}
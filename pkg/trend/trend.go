@@ -0,0 +1,385 @@
+// Package trend 基于 Prometheus 一周的历史数据分析容器资源使用趋势并给出推荐值
+package trend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"k8stools/pkg/config"
+	"k8stools/pkg/kube"
+	"k8stools/pkg/quota"
+	"k8stools/pkg/recommend"
+	"k8stools/pkg/workload"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// TrendAdvice 单个容器的资源趋势与推荐值
+type TrendAdvice struct {
+	// Cluster 分析的集群，没有集群客户端时为空
+	Cluster    string  `json:"cluster" header:"Cluster"`
+	Namespace  string  `json:"namespace" header:"Namespace"`
+	Kind       string  `json:"kind" header:"Kind"`
+	Workload   string  `json:"workload" header:"Workload"`
+	Container  string  `json:"container" header:"Container"`
+	Trend      string  `json:"trend" header:"趋势标签"`
+	Slope      float64 `json:"slope" header:"趋势斜率"`
+	CPURequest int     `json:"cpuRequest" header:"推荐CPU Requests(m)"`
+	CPULimit   int     `json:"cpuLimit" header:"推荐CPU Limits(m)"`
+	MemRequest int     `json:"memRequest" header:"推荐Memory Requests(Mi)"`
+	MemLimit   int     `json:"memLimit" header:"推荐Memory Limits(Mi)"`
+	Date       string  `json:"date" header:"日期"`
+	AvgCPU     float64 `json:"avgCpu" header:"平均CPU(m)" fmt:"%.0f"`
+	MaxCPU     float64 `json:"maxCpu" header:"最大CPU(m)" fmt:"%.0f"`
+	AvgMem     float64 `json:"avgMem" header:"平均内存(Mi)" fmt:"%.0f"`
+	MaxMem     float64 `json:"maxMem" header:"最大内存(Mi)" fmt:"%.0f"`
+	Policy     string  `json:"policy" header:"策略"`
+	Basis      string  `json:"basis" header:"计算依据"`
+	// Compliance 按命名空间 LimitRange 修正建议值或预计超出 ResourceQuota 的说明，没有集群客户端时为空
+	Compliance string `json:"compliance" header:"合规说明"`
+}
+
+// builtinPolicy 未配置 recommend 策略时 Policy 列显示的名称，表示按平均值、最大值及趋势计算
+const builtinPolicy = "trend"
+
+// GetTrend 校验配置后分析配置的命名空间下所有容器的资源趋势
+// kc 不为 nil 时按 ownerReferences 确定 Pod 所属的工作负载；kc 为 nil、Pod 已不存在或获取失败时，
+// 按 Pod 名称推测 Deployment 名称，此时 Kind 为空。获取归属失败以警告错误返回，结果仍然可用
+// kc 不为 nil 时建议值还会按命名空间的 LimitRange 修正，并检查是否超出 ResourceQuota
+func GetTrend(ctx context.Context, c *config.Config, kc kube.Client) ([]TrendAdvice, error) {
+	if err := ValidateConfig(c); err != nil {
+		return nil, fmt.Errorf("配置验证失败: %w", err)
+	}
+	selector, err := recommend.NewSelector(c.Recommend)
+	if err != nil {
+		return nil, fmt.Errorf("recommend 策略配置错误: %w", err)
+	}
+
+	owners := make(map[string]workload.Owner)
+	var errs []error
+	if kc != nil {
+		for _, ns := range c.NameSpace {
+			nsOwners, err := workload.PodOwners(ctx, kc.Kubernetes(), ns)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("命名空间 %s: %w", ns, err))
+				continue
+			}
+			for pod, owner := range nsOwners {
+				owners[ns+"/"+pod] = owner
+			}
+		}
+	}
+
+	rows, err := AnalyzeResourceTrends(ctx, c.Prometheus, c.NameSpace, owners, selector)
+	if err != nil {
+		return nil, fmt.Errorf("趋势分析失败: %w", err)
+	}
+	if kc != nil {
+		for i := range rows {
+			rows[i].Cluster = kc.Name()
+		}
+		for _, ns := range c.NameSpace {
+			if err := enforce(ctx, kc, ns, rows); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return rows, errors.Join(errs...)
+}
+
+// enforce 按命名空间 ns 的 LimitRange 与 ResourceQuota 校验 rows 中属于该命名空间的建议，修正后的值与说明写回 rows
+func enforce(ctx context.Context, kc kube.Client, ns string, rows []TrendAdvice) error {
+	var idx []int
+	var items []quota.Item
+	for i, r := range rows {
+		if r.Namespace != ns {
+			continue
+		}
+		idx = append(idx, i)
+		items = append(items, quota.Item{
+			Kind:       r.Kind,
+			Workload:   r.Workload,
+			Container:  r.Container,
+			CPURequest: int64(r.CPURequest),
+			CPULimit:   int64(r.CPULimit),
+			MemRequest: int64(r.MemRequest),
+			MemLimit:   int64(r.MemLimit),
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	workloads, _, listErr := workload.List(ctx, kc.Kubernetes(), ns)
+	cons, err := quota.Load(ctx, kc.Kubernetes(), ns)
+	cons.Enforce(items, workloads)
+	for j, i := range idx {
+		it := &items[j]
+		rows[i].CPURequest, rows[i].CPULimit = int(it.CPURequest), int(it.CPULimit)
+		rows[i].MemRequest, rows[i].MemLimit = int(it.MemRequest), int(it.MemLimit)
+		rows[i].Compliance = it.Reason()
+	}
+	if listErr != nil {
+		listErr = fmt.Errorf("命名空间 %s: %w", ns, listErr)
+	}
+	return errors.Join(listErr, err)
+}
+
+// ValidateConfig 校验 trend 所需的配置，问题汇总在 *config.ValidationError 中
+func ValidateConfig(c *config.Config) error {
+	return config.Validate(c, "trend")
+}
+
+// AnalyzeResourceTrends 查询 Prometheus 一周的数据生成趋势建议，owners 的键为 "命名空间/Pod"
+// selector 中匹配到策略时按该策略计算建议值（不再按趋势调整），selector 为 nil 或未匹配时使用内置算法
+func AnalyzeResourceTrends(ctx context.Context, promAddress string, namespaces []string, owners map[string]workload.Owner, selector *recommend.Selector) ([]TrendAdvice, error) {
+	// 创建 Prometheus API client
+	client, err := api.NewClient(api.Config{
+		Address: promAddress,
+		RoundTripper: &http.Transport{
+			MaxIdleConns:    10,
+			IdleConnTimeout: 30 * time.Second,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 Prometheus 客户端失败: %w", err)
+	}
+
+	api := v1.NewAPI(client)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// 构建 namespace 正则
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("命名空间列表不能为空")
+	}
+
+	nsFilter := ""
+	for i, ns := range namespaces {
+		if i > 0 {
+			nsFilter += "|"
+		}
+		nsFilter += ns
+	}
+	filter := fmt.Sprintf(`namespace=~"%s"`, nsFilter)
+
+	// 查询表达式：可自定义
+	cpuQuery := fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{%s,image!="",container!="POD"}[5m])) by (namespace, pod, container)`, filter)
+	memQuery := fmt.Sprintf(`avg(container_memory_usage_bytes{%s,image!="",container!="POD"}) by (namespace, pod, container)`, filter)
+
+	// 执行 Prometheus 查询
+	cpuResult, cpuWarnings, err := api.QueryRange(ctx, cpuQuery, v1.Range{
+		Start: time.Now().Add(-7 * 24 * time.Hour),
+		End:   time.Now(),
+		Step:  time.Hour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询 CPU 失败: %w", err)
+	}
+	if len(cpuWarnings) > 0 {
+		fmt.Fprintf(os.Stderr, "CPU 查询警告: %v\n", cpuWarnings)
+	}
+
+	memResult, memWarnings, err := api.QueryRange(ctx, memQuery, v1.Range{
+		Start: time.Now().Add(-7 * 24 * time.Hour),
+		End:   time.Now(),
+		Step:  time.Hour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询内存失败: %w", err)
+	}
+	if len(memWarnings) > 0 {
+		fmt.Fprintf(os.Stderr, "内存查询警告: %v\n", memWarnings)
+	}
+
+	// 构建指标映射
+	cpuData := parseMatrix(cpuResult, false) // CPU: 不需要转换单位，只乘以 1000 转成 milli
+	memData := parseMatrix(memResult, true)  // 内存: 需要从 Bytes 转成 MiB
+
+	// 遍历数据生成推荐
+	var rows []TrendAdvice
+	for key, cpuSeries := range cpuData {
+		memSeries := memData[key]
+
+		avgCPU, maxCPU := calcAvgMax(cpuSeries)
+		avgMem, maxMem := calcAvgMax(memSeries)
+
+		// 推荐值计算
+		recommendCPUReq := int(avgCPU * 1.2)
+		recommendCPULim := int(maxCPU * 1.5)
+		recommendMemReq := int(avgMem * 1.2)
+		recommendMemLim := int(maxMem * 1.5)
+
+		// 获取其他信息
+		ns := key.namespace
+		owner, ok := owners[ns+"/"+key.pod]
+		if !ok {
+			// 历史数据中的 Pod 可能已被删除，只能按名称推测
+			owner = workload.Owner{Name: extractDeployment(key.pod)}
+		}
+		trend, trendSlope := analyzeTrend(cpuSeries) // 趋势标签和斜率
+		
+		// 基于趋势调整推荐值，计算依据中的 Requests 表达式包含实际使用的趋势系数
+		requestExpr := "avg * 1.2"
+		if factor := trendFactor(trend); factor != 1 {
+			recommendCPUReq = int(float64(recommendCPUReq) * factor)
+			recommendMemReq = int(float64(recommendMemReq) * factor)
+			requestExpr = fmt.Sprintf("avg * 1.2 * %g", factor)
+		}
+		policy := builtinPolicy
+		basis := fmt.Sprintf("cpuRequest=%s(=%dm); cpuLimit=max * 1.5(=%dm); memRequest=%s(=%dMi); memLimit=max * 1.5(=%dMi)",
+			requestExpr, recommendCPUReq, recommendCPULim, requestExpr, recommendMemReq, recommendMemLim)
+		if r, ok := selector.Lookup(ns, owner.Name); ok {
+			rec := r.Recommend(recommend.Usage{CPU: cpuSeries, Memory: memSeries})
+			recommendCPUReq, recommendCPULim = int(rec.CPURequest), int(rec.CPULimit)
+			recommendMemReq, recommendMemLim = int(rec.MemRequest), int(rec.MemLimit)
+			policy, basis = rec.Policy, rec.Basis
+		}
+		
+		rows = append(rows, TrendAdvice{
+			Namespace:  ns,
+			Kind:       owner.Kind,
+			Workload:   owner.Name,
+			Container:  key.container,
+			Trend:      trend,
+			Slope:      trendSlope,
+			CPURequest: recommendCPUReq,
+			CPULimit:   recommendCPULim,
+			MemRequest: recommendMemReq,
+			MemLimit:   recommendMemLim,
+			Date:       time.Now().Format("2006-01-02"),
+			AvgCPU:     avgCPU,
+			MaxCPU:     maxCPU,
+			AvgMem:     avgMem,
+			MaxMem:     maxMem,
+			Policy:     policy,
+			Basis:      basis,
+		})
+	}
+
+	return rows, nil
+}
+
+type metricKey struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// 解析 Prometheus 查询结果
+func parseMatrix(val model.Value, convertToMi bool) map[metricKey][]float64 {
+	res := make(map[metricKey][]float64)
+	matrix, ok := val.(model.Matrix)
+	if !ok {
+		return res
+	}
+	for _, stream := range matrix {
+		key := metricKey{
+			namespace: string(stream.Metric["namespace"]),
+			pod:       string(stream.Metric["pod"]),
+			container: string(stream.Metric["container"]),
+		}
+		for _, v := range stream.Values {
+			value := float64(v.Value)
+			if convertToMi {
+				value = value / (1024 * 1024) // Bytes -> Mi
+			} else {
+				value = value * 1000 // CPU: cores -> milli-cores
+			}
+			res[key] = append(res[key], value)
+		}
+	}
+	return res
+}
+
+// 计算平均值和最大值
+func calcAvgMax(data []float64) (avg, max float64) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	total := 0.0
+	max = data[0]
+	for _, v := range data {
+		total += v
+		if v > max {
+			max = v
+		}
+	}
+	avg = total / float64(len(data))
+	return
+}
+
+// 分析趋势
+func analyzeTrend(data []float64) (string, float64) {
+	if len(data) < 10 {
+		return "数据不足", 0
+	}
+	
+	// 使用线性回归分析趋势
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range data {
+		x := float64(i)
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	
+	n := float64(len(data))
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	
+	// 计算置信度
+	meanY := sumY / n
+	var sumSqDiff float64
+	for _, v := range data {
+		sumSqDiff += (v - meanY) * (v - meanY)
+	}
+	variance := sumSqDiff / n
+	
+	// 判断趋势
+	if slope > 0.1 && variance > 10 {
+		return "上升趋势", slope
+	} else if slope < -0.1 && variance > 10 {
+		return "下降趋势", slope
+	}
+	return "稳定", slope
+}
+
+// trendFactor 返回趋势对应的 Requests 调整系数：上升趋势 ×1.1，下降趋势 ×0.9
+func trendFactor(trend string) float64 {
+	switch trend {
+	case "上升趋势":
+		return 1.1
+	case "下降趋势":
+		return 0.9
+	}
+	return 1
+}
+
+// 提取 Deployment 名称，仅在无法通过 ownerReferences 确定归属时使用
+func extractDeployment(pod string) string {
+	// 假设 deployment 名为 pod-name 的前缀
+	// 比如 xxx-7f9cd5b477-abc12 => xxx
+	if i := len(pod); i > 0 {
+		if dash := lastIndex(pod, "-"); dash > 0 {
+			if second := lastIndex(pod[:dash], "-"); second > 0 {
+				return pod[:second]
+			}
+		}
+	}
+	return pod
+}
+
+// 查找分隔符位置
+func lastIndex(s string, sep string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if string(s[i]) == sep {
+			return i
+		}
+	}
+	return -1
+}
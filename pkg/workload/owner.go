@@ -0,0 +1,117 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Owner Pod 所属的顶层工作负载
+type Owner struct {
+	Kind string
+	Name string
+	UID  types.UID
+}
+
+// Key 返回 "Kind/Name"，与 Workload.Key 对应
+func (o Owner) Key() string {
+	return o.Kind + "/" + o.Name
+}
+
+// OwnerResolver 沿 ownerReferences 将 Pod 映射到顶层工作负载：
+// Pod→ReplicaSet→Deployment、Pod→Job→CronJob，StatefulSet、DaemonSet 等直接取 Pod 的控制器
+type OwnerResolver struct {
+	// 中间层对象 UID -> 其控制器，为 nil 表示没有上层控制器
+	replicaSets map[types.UID]*metav1.OwnerReference
+	jobs        map[types.UID]*metav1.OwnerReference
+}
+
+// NewOwnerResolver 基于已获取的 ReplicaSet 与 Job 构建解析器
+func NewOwnerResolver(replicaSets []appsv1.ReplicaSet, jobs []batchv1.Job) *OwnerResolver {
+	r := &OwnerResolver{
+		replicaSets: make(map[types.UID]*metav1.OwnerReference, len(replicaSets)),
+		jobs:        make(map[types.UID]*metav1.OwnerReference, len(jobs)),
+	}
+	for i := range replicaSets {
+		r.replicaSets[replicaSets[i].UID] = metav1.GetControllerOf(&replicaSets[i])
+	}
+	for i := range jobs {
+		r.jobs[jobs[i].UID] = metav1.GetControllerOf(&jobs[i])
+	}
+	return r
+}
+
+// LoadOwnerResolver 获取命名空间下的 ReplicaSet 与 Job 并构建解析器
+func LoadOwnerResolver(ctx context.Context, clientset kubernetes.Interface, ns string) (*OwnerResolver, error) {
+	replicaSets, err := clientset.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 ReplicaSet 失败: %w", err)
+	}
+	jobs, err := clientset.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Job 失败: %w", err)
+	}
+	return NewOwnerResolver(replicaSets.Items, jobs.Items), nil
+}
+
+// Resolve 返回 Pod 的顶层工作负载，没有控制器的 Pod 返回 false
+// 中间层对象不在解析器中时（如已被删除或快照中未包含），ReplicaSet 按 pod-template-hash 命名规则推断 Deployment，
+// 其余情况返回中间层本身
+func (r *OwnerResolver) Resolve(pod *corev1.Pod) (Owner, bool) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return Owner{}, false
+	}
+	switch ref.Kind {
+	case KindReplicaSet:
+		if parent, ok := r.replicaSets[ref.UID]; ok {
+			if parent != nil {
+				ref = parent
+			}
+		} else if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+			return Owner{Kind: KindDeployment, Name: strings.TrimSuffix(ref.Name, "-"+hash)}, true
+		}
+	case KindJob:
+		if parent := r.jobs[ref.UID]; parent != nil {
+			ref = parent
+		}
+	}
+	return Owner{Kind: ref.Kind, Name: ref.Name, UID: ref.UID}, true
+}
+
+// Group 按顶层工作负载的 Key 对 Pod 分组，没有控制器的 Pod 不参与分组
+func (r *OwnerResolver) Group(pods []corev1.Pod) map[string][]corev1.Pod {
+	groups := make(map[string][]corev1.Pod)
+	for i := range pods {
+		if owner, ok := r.Resolve(&pods[i]); ok {
+			groups[owner.Key()] = append(groups[owner.Key()], pods[i])
+		}
+	}
+	return groups
+}
+
+// PodOwners 获取命名空间下全部 Pod 的顶层工作负载，键为 Pod 名称，没有控制器的 Pod 不包含在内
+func PodOwners(ctx context.Context, clientset kubernetes.Interface, ns string) (map[string]Owner, error) {
+	pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Pod 失败: %w", err)
+	}
+	resolver, err := LoadOwnerResolver(ctx, clientset, ns)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]Owner, len(pods.Items))
+	for i := range pods.Items {
+		if owner, ok := resolver.Resolve(&pods.Items[i]); ok {
+			owners[pods.Items[i].Name] = owner
+		}
+	}
+	return owners, nil
+}
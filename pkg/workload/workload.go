@@ -0,0 +1,158 @@
+// Package workload 将 Deployment、StatefulSet、DaemonSet、ReplicaSet、Job、CronJob 统一抽象为工作负载，
+// 并沿 ownerReferences 将 Pod 归属到顶层工作负载
+package workload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 支持的工作负载类型
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindReplicaSet  = "ReplicaSet"
+	KindJob         = "Job"
+	KindCronJob     = "CronJob"
+)
+
+// Kinds 全部支持的工作负载类型，按 List 的输出顺序排列
+var Kinds = []string{KindDeployment, KindStatefulSet, KindDaemonSet, KindReplicaSet, KindJob, KindCronJob}
+
+// Workload 工作负载的公共信息
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	UID       types.UID
+	// Labels、Annotations 工作负载自身（而非 Pod 模板）的标签与注解
+	Labels      map[string]string
+	Annotations map[string]string
+	// Replicas 期望副本数：DaemonSet 为期望调度的节点数，Job/CronJob 为并行度
+	Replicas int32
+	// Template Pod 模板
+	Template corev1.PodTemplateSpec
+}
+
+// Key 返回 "Kind/Name"，用于与 OwnerResolver 的归属结果对应
+func (w *Workload) Key() string {
+	return w.Kind + "/" + w.Name
+}
+
+// List 获取命名空间下全部类型的工作负载，并基于同时获取的 ReplicaSet、Job 构建 Pod 归属解析器
+// 由 Deployment 管理的 ReplicaSet、由 CronJob 创建的 Job 不单独列出；
+// 个别类型获取失败时返回其余类型的结果以及合并后的错误
+func List(ctx context.Context, clientset kubernetes.Interface, ns string) ([]Workload, *OwnerResolver, error) {
+	return list(ctx, clientset, ns, metav1.ListOptions{})
+}
+
+// ListSelected 与 List 相同，只返回标签匹配 selector 的工作负载；
+// ReplicaSet、Job 同样按 selector 过滤，返回的归属解析器只适用于匹配的工作负载
+func ListSelected(ctx context.Context, clientset kubernetes.Interface, ns, selector string) ([]Workload, *OwnerResolver, error) {
+	return list(ctx, clientset, ns, metav1.ListOptions{LabelSelector: selector})
+}
+
+func list(ctx context.Context, clientset kubernetes.Interface, ns string, opts metav1.ListOptions) ([]Workload, *OwnerResolver, error) {
+	var workloads []Workload
+	var errs []error
+
+	deployments, err := clientset.AppsV1().Deployments(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 Deployment 失败: %w", err))
+	} else {
+		for _, d := range deployments.Items {
+			workloads = appendWorkload(workloads, KindDeployment, d.ObjectMeta, replicas(d.Spec.Replicas), d.Spec.Template)
+		}
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 StatefulSet 失败: %w", err))
+	} else {
+		for _, s := range statefulSets.Items {
+			workloads = appendWorkload(workloads, KindStatefulSet, s.ObjectMeta, replicas(s.Spec.Replicas), s.Spec.Template)
+		}
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 DaemonSet 失败: %w", err))
+	} else {
+		for _, d := range daemonSets.Items {
+			workloads = appendWorkload(workloads, KindDaemonSet, d.ObjectMeta, d.Status.DesiredNumberScheduled, d.Spec.Template)
+		}
+	}
+
+	var replicaSets []appsv1.ReplicaSet
+	rsList, err := clientset.AppsV1().ReplicaSets(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 ReplicaSet 失败: %w", err))
+	} else {
+		replicaSets = rsList.Items
+		for _, r := range replicaSets {
+			if metav1.GetControllerOf(&r) != nil {
+				continue
+			}
+			workloads = appendWorkload(workloads, KindReplicaSet, r.ObjectMeta, replicas(r.Spec.Replicas), r.Spec.Template)
+		}
+	}
+
+	var jobs []batchv1.Job
+	jobList, err := clientset.BatchV1().Jobs(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 Job 失败: %w", err))
+	} else {
+		jobs = jobList.Items
+		for _, j := range jobs {
+			if metav1.GetControllerOf(&j) != nil {
+				continue
+			}
+			workloads = appendWorkload(workloads, KindJob, j.ObjectMeta, parallelism(j.Spec), j.Spec.Template)
+		}
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(ns).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("获取 CronJob 失败: %w", err))
+	} else {
+		for _, cj := range cronJobs.Items {
+			workloads = appendWorkload(workloads, KindCronJob, cj.ObjectMeta, parallelism(cj.Spec.JobTemplate.Spec), cj.Spec.JobTemplate.Spec.Template)
+		}
+	}
+
+	return workloads, NewOwnerResolver(replicaSets, jobs), errors.Join(errs...)
+}
+
+func appendWorkload(workloads []Workload, kind string, meta metav1.ObjectMeta, replicas int32, template corev1.PodTemplateSpec) []Workload {
+	return append(workloads, Workload{
+		Kind:        kind,
+		Namespace:   meta.Namespace,
+		Name:        meta.Name,
+		UID:         meta.UID,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+		Replicas:    replicas,
+		Template:    template,
+	})
+}
+
+// replicas 未设置副本数时与 API Server 默认值一致，按 1 计算
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+func parallelism(spec batchv1.JobSpec) int32 {
+	return replicas(spec.Parallelism)
+}
@@ -0,0 +1,119 @@
+package workload
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const ns = "demo"
+
+func meta(name string, uid types.UID, owner *metav1.OwnerReference) metav1.ObjectMeta {
+	m := metav1.ObjectMeta{Namespace: ns, Name: name, UID: uid}
+	if owner != nil {
+		m.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return m
+}
+
+func controller(kind, name string, uid types.UID) *metav1.OwnerReference {
+	isController := true
+	return &metav1.OwnerReference{Kind: kind, Name: name, UID: uid, Controller: &isController}
+}
+
+func int32Ptr(v int32) *int32 { return &v }
+
+// testObjects 覆盖全部工作负载类型：Deployment 管理的 ReplicaSet、CronJob 创建的 Job 不应单独列出
+func testObjects() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: meta("web", "d-web", nil), Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(3)}},
+		&appsv1.ReplicaSet{ObjectMeta: meta("web-7d9f", "rs-web", controller(KindDeployment, "web", "d-web"))},
+		&appsv1.ReplicaSet{ObjectMeta: meta("bare", "rs-bare", nil)},
+		&appsv1.StatefulSet{ObjectMeta: meta("db", "s-db", nil), Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(2)}},
+		&appsv1.DaemonSet{ObjectMeta: meta("agent", "ds-agent", nil), Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 5}},
+		&batchv1.CronJob{ObjectMeta: meta("backup", "cj-backup", nil)},
+		&batchv1.Job{ObjectMeta: meta("backup-2900", "j-backup", controller(KindCronJob, "backup", "cj-backup"))},
+		&batchv1.Job{ObjectMeta: meta("migrate", "j-migrate", nil), Spec: batchv1.JobSpec{Parallelism: int32Ptr(4)}},
+	}
+}
+
+func TestList(t *testing.T) {
+	workloads, _, err := List(context.Background(), fake.NewSimpleClientset(testObjects()...), ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int32)
+	for _, w := range workloads {
+		got[w.Key()] = w.Replicas
+	}
+	want := map[string]int32{
+		"Deployment/web":  3,
+		"StatefulSet/db":  2,
+		"DaemonSet/agent": 5,
+		"ReplicaSet/bare": 1,
+		"Job/migrate":     4,
+		"CronJob/backup":  1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestOwnerResolverGroup(t *testing.T) {
+	pod := func(name string, owner *metav1.OwnerReference, labels map[string]string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: meta(name, types.UID("p-"+name), owner)}
+		p.Labels = labels
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("web-7d9f-a", controller(KindReplicaSet, "web-7d9f", "rs-web"), nil),
+		pod("web-7d9f-b", controller(KindReplicaSet, "web-7d9f", "rs-web"), nil),
+		// ReplicaSet 已被删除时按 pod-template-hash 推断 Deployment
+		pod("api-5c6b-a", controller(KindReplicaSet, "api-5c6b", "rs-gone"), map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5c6b"}),
+		// 无法推断时归属到 ReplicaSet 本身
+		pod("orphan-a", controller(KindReplicaSet, "orphan", "rs-orphan"), nil),
+		pod("bare-a", controller(KindReplicaSet, "bare", "rs-bare"), nil),
+		pod("db-0", controller(KindStatefulSet, "db", "s-db"), nil),
+		pod("agent-x", controller(KindDaemonSet, "agent", "ds-agent"), nil),
+		pod("backup-2900-a", controller(KindJob, "backup-2900", "j-backup"), nil),
+		pod("migrate-a", controller(KindJob, "migrate", "j-migrate"), nil),
+		// 没有控制器的 Pod 不参与分组
+		pod("standalone", nil, nil),
+	}
+
+	_, resolver, err := List(context.Background(), fake.NewSimpleClientset(testObjects()...), ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := resolver.Group(pods)
+
+	want := map[string][]string{
+		"Deployment/web":    {"web-7d9f-a", "web-7d9f-b"},
+		"Deployment/api":    {"api-5c6b-a"},
+		"ReplicaSet/orphan": {"orphan-a"},
+		"ReplicaSet/bare":   {"bare-a"},
+		"StatefulSet/db":    {"db-0"},
+		"DaemonSet/agent":   {"agent-x"},
+		"CronJob/backup":    {"backup-2900-a"},
+		"Job/migrate":       {"migrate-a"},
+	}
+	got := make(map[string][]string, len(groups))
+	for key, ps := range groups {
+		for _, p := range ps {
+			got[key] = append(got[key], p.Name)
+		}
+		sort.Strings(got[key])
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Group() = %v, want %v", got, want)
+	}
+}